@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/router"
@@ -30,12 +31,17 @@ func main() {
 	}()
 	conf := config.NewConfig()
 	connectionStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", conf.DB.DB_USER, conf.DB.DB_PASSWORD, conf.DB.DB_HOST, strconv.Itoa(conf.DB.DB_PORT), conf.DB.DB_NAME)
-	songHandler, err := initialization.InitializeComponentsSong(connectionStr, lgr)
+	components, err := initialization.InitializeComponentsSong(connectionStr, conf, lgr)
 	if err != nil {
 		panic(fmt.Errorf("Initialization has failed: %s\n", err))
 	}
 	lgr.InfoLogger.Println("Initialization components for router has successfully")
-	app := router.NewFiberRouter(songHandler, conf.API.API_PORT)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, w := range components.Workers {
+		go w.Run(ctx)
+	}
+	app := router.NewFiberRouter(components.SongHandler, components.JobHandler, components.CacheHandler, components.Idempotency, conf.API.API_PORT, lgr)
 	lgr.DebugLogger.Println("Launching the application.....")
 	app.Listen(fmt.Sprintf(":%s", strconv.Itoa(conf.API.API_PORT)))
 
@@ -54,5 +54,8 @@ func main() {
 		panic(fmt.Errorf("Applying migrations has failed: %s\n", err))
 	}
 	lgr.InfoLogger.Println("Applying migrations has successfully")
+	if err := migrator.ReportReleaseDateBackfill(connection, lgr); err != nil {
+		lgr.ErrorLogger.Printf("Reporting release date backfill has failed: %s\n", err)
+	}
 
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/mockinfo"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/joho/godotenv"
+	"path/filepath"
+	"strconv"
+)
+
+var lgr *logger.Logger = logger.NewLogger()
+
+func init() {
+
+	envPath := filepath.Join(".env")
+	if err := godotenv.Load(envPath); err != nil {
+		lgr.DebugLogger.Println("Not found .env file")
+	} else {
+		lgr.InfoLogger.Println(".env file was found")
+	}
+}
+func main() {
+	conf := config.NewMockInfoConfig()
+	fixtures, err := mockinfo.LoadFixtures(conf.MOCKINFO_FIXTURES_FILE)
+	if err != nil {
+		panic(fmt.Errorf("Loading fixtures has failed: %s\n", err))
+	}
+	lgr.InfoLogger.Printf("Loaded %d fixtures from %s\n", len(fixtures), conf.MOCKINFO_FIXTURES_FILE)
+	lgr.InfoLogger.Printf("Latency: %dms + up to %dms, error rate: %.2f, not found rate: %.2f\n",
+		conf.MOCKINFO_LATENCY_MS, conf.MOCKINFO_LATENCY_JITTER_MS, conf.MOCKINFO_ERROR_RATE, conf.MOCKINFO_NOT_FOUND_RATE)
+	app := mockinfo.NewServer(fixtures, *conf, lgr)
+	lgr.DebugLogger.Println("Launching the mock song info service.....")
+	if err := app.Listen(fmt.Sprintf(":%s", strconv.Itoa(conf.MOCKINFO_PORT))); err != nil {
+		panic(fmt.Errorf("Mock song info service has failed: %s\n", err))
+	}
+}
@@ -0,0 +1,58 @@
+package apperrors
+
+import "errors"
+
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrConflict            = errors.New("conflict")
+	ErrPreconditionFailed  = errors.New("precondition failed")
+	ErrValidation          = errors.New("validation failed")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	ErrUpstreamNotFound    = errors.New("upstream not found")
+)
+
+// Error carries one of the sentinel kinds above together with a message that
+// is safe to show to API clients. errors.Is matches it against its kind and
+// against the wrapped cause. Extensions are extra members of the problem
+// details, e.g. the ID of the song a conflict is about.
+type Error struct {
+	kind       error
+	Message    string
+	Extensions map[string]interface{}
+	err        error
+}
+
+func New(kind error, message string) *Error {
+	return &Error{kind: kind, Message: message}
+}
+
+func Wrap(kind error, message string, err error) *Error {
+	return &Error{kind: kind, Message: message, err: err}
+}
+
+func (e *Error) WithExtension(key string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = make(map[string]interface{})
+	}
+	e.Extensions[key] = value
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.err != nil {
+		return e.Message + ": " + e.err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+func (e *Error) Kind() error {
+	return e.kind
+}
@@ -0,0 +1,22 @@
+// Package audit carries the editor of the current request down to the
+// repository, which records it in the song history.
+package audit
+
+import "context"
+
+const HeaderEditor = "X-Editor"
+
+type editorKey struct{}
+
+// EditorKey is the context key of the editor. Handlers store it with
+// c.Locals so that it is visible through c.Context().
+var EditorKey = editorKey{}
+
+func WithEditor(ctx context.Context, editor string) context.Context {
+	return context.WithValue(ctx, EditorKey, editor)
+}
+
+func Editor(ctx context.Context) string {
+	editor, _ := ctx.Value(EditorKey).(string)
+	return editor
+}
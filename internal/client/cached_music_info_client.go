@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/lru"
+)
+
+type cacheBypassKey struct{}
+
+// WithoutCache makes lookups with ctx skip the cache, e.g. when a song is
+// refreshed on purpose. The fresh result is still cached.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// CachedMusicInfoClient is a MusicInfoClient with a cache in front of it.
+type CachedMusicInfoClient interface {
+	MusicInfoClient
+	Stats() model.SongInfoCacheStats
+}
+
+// songInfoKey ignores case and surrounding spaces, the way FindSong matches
+// songs.
+type songInfoKey struct {
+	group string
+	song  string
+}
+
+func newSongInfoKey(group string, song string) songInfoKey {
+	return songInfoKey{group: strings.ToLower(strings.TrimSpace(group)), song: strings.ToLower(strings.TrimSpace(song))}
+}
+
+type cachedMusicInfoClient struct {
+	next        MusicInfoClient
+	memory      *lru.Cache[songInfoKey, model.CachedSongDetail]
+	persistent  repository.SongInfoCacheRepository
+	ttl         time.Duration
+	negativeTTL time.Duration
+	stats       struct {
+		memoryHits, persistentHits, negativeHits, misses, bypassed atomic.Int64
+	}
+	lgr *logger.Logger
+}
+
+// NewCachedMusicInfoClient caches lookups of next in memory and, when
+// persistent is not nil, in Postgres. Songs the service has no data for and
+// degraded details are cached for the shorter negative TTL; failed lookups
+// are not cached.
+func NewCachedMusicInfoClient(next MusicInfoClient, persistent repository.SongInfoCacheRepository, conf config.SongInfoCacheConfig, lgr *logger.Logger) CachedMusicInfoClient {
+	return &cachedMusicInfoClient{
+		next:        next,
+		memory:      lru.New[songInfoKey, model.CachedSongDetail](conf.INFO_CACHE_SIZE),
+		persistent:  persistent,
+		ttl:         time.Duration(conf.INFO_CACHE_TTL_MINUTES) * time.Minute,
+		negativeTTL: time.Duration(conf.INFO_CACHE_NEGATIVE_TTL_MINUTES) * time.Minute,
+		lgr:         lgr,
+	}
+}
+
+func (cc *cachedMusicInfoClient) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	key := newSongInfoKey(group, song)
+	if cacheBypassed(ctx) {
+		cc.stats.bypassed.Add(1)
+		return cc.lookup(ctx, key, group, song)
+	}
+
+	if cached, ok := cc.memory.Get(key); ok {
+		cc.stats.memoryHits.Add(1)
+		return cc.fromCache(cached, group, song)
+	}
+	if cc.persistent != nil {
+		cached, expiresAt, err := cc.persistent.GetSongDetail(ctx, key.group, key.song)
+		if err == nil && cached != nil {
+			cc.stats.persistentHits.Add(1)
+			cc.memory.Set(key, *cached, time.Until(expiresAt))
+			return cc.fromCache(*cached, group, song)
+		}
+	}
+
+	cc.stats.misses.Add(1)
+	return cc.lookup(ctx, key, group, song)
+}
+
+func (cc *cachedMusicInfoClient) Stats() model.SongInfoCacheStats {
+	return model.SongInfoCacheStats{
+		MemoryHits:     cc.stats.memoryHits.Load(),
+		PersistentHits: cc.stats.persistentHits.Load(),
+		NegativeHits:   cc.stats.negativeHits.Load(),
+		Misses:         cc.stats.misses.Load(),
+		Bypassed:       cc.stats.bypassed.Load(),
+		Entries:        cc.memory.Len(),
+		Persistent:     cc.persistent != nil,
+	}
+}
+
+func (cc *cachedMusicInfoClient) lookup(ctx context.Context, key songInfoKey, group string, song string) (*model.SongDetail, error) {
+	songDetail, err := cc.next.GetSongDetail(ctx, group, song)
+	switch {
+	case err == nil && songDetail.Degraded:
+		cc.store(ctx, key, model.CachedSongDetail{SongDetail: songDetail}, cc.negativeTTL)
+	case err == nil:
+		cc.store(ctx, key, model.CachedSongDetail{SongDetail: songDetail}, cc.ttl)
+	case errors.Is(err, ErrNotFound):
+		cc.store(ctx, key, model.CachedSongDetail{}, cc.negativeTTL)
+	}
+	return songDetail, err
+}
+
+func (cc *cachedMusicInfoClient) store(ctx context.Context, key songInfoKey, cached model.CachedSongDetail, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	cc.memory.Set(key, cached, ttl)
+	if cc.persistent != nil {
+		cc.persistent.SaveSongDetail(ctx, key.group, key.song, cached, time.Now().Add(ttl))
+	}
+}
+
+func (cc *cachedMusicInfoClient) fromCache(cached model.CachedSongDetail, group string, song string) (*model.SongDetail, error) {
+	cc.lgr.DebugLogger.Printf("Song info for %s - %s served from the cache\n", group, song)
+	if cached.SongDetail == nil {
+		cc.stats.negativeHits.Add(1)
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamNotFound, fmt.Sprintf("song info service has no data for %s - %s", group, song), ErrNotFound)
+	}
+	songDetail := *cached.SongDetail
+	return &songDetail, nil
+}
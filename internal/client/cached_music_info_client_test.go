@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+// fakeMusicInfoClient answers from details and counts the calls. Songs it
+// has no detail for are not found, the song "down" fails and the details of
+// the song "partial" are degraded.
+type fakeMusicInfoClient struct {
+	details map[string]model.SongDetail
+	calls   []string
+}
+
+func (fc *fakeMusicInfoClient) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	fc.calls = append(fc.calls, group+" - "+song)
+	if song == "down" {
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamUnavailable, "song info service is unavailable", errors.New("connection refused"))
+	}
+	detail, ok := fc.details[strings.ToLower(group+" - "+song)]
+	if !ok {
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamNotFound, "song info service has no data", ErrNotFound)
+	}
+	detail.Degraded = song == "partial"
+	return &detail, nil
+}
+
+type lookupStep struct {
+	group, song string
+	bypass      bool
+	wait        time.Duration
+	wantErr     error
+}
+
+func TestCachedMusicInfoClient(t *testing.T) {
+	uprising := model.SongDetail{Text: "Paranoia is in bloom", Link: "https://example.com/uprising"}
+	tests := []struct {
+		name      string
+		steps     []lookupStep
+		wantCalls []string
+		wantStats model.SongInfoCacheStats
+	}{
+		{
+			name: "second lookup is a memory hit",
+			steps: []lookupStep{
+				{group: "Muse", song: "Uprising"},
+				{group: "Muse", song: "Uprising"},
+			},
+			wantCalls: []string{"Muse - Uprising"},
+			wantStats: model.SongInfoCacheStats{MemoryHits: 1, Misses: 1, Entries: 1},
+		},
+		{
+			name: "key ignores case and spaces",
+			steps: []lookupStep{
+				{group: "Muse", song: "Uprising"},
+				{group: " muse ", song: "UPRISING"},
+			},
+			wantCalls: []string{"Muse - Uprising"},
+			wantStats: model.SongInfoCacheStats{MemoryHits: 1, Misses: 1, Entries: 1},
+		},
+		{
+			name: "not found is cached",
+			steps: []lookupStep{
+				{group: "Muse", song: "Unknown", wantErr: apperrors.ErrUpstreamNotFound},
+				{group: "Muse", song: "Unknown", wantErr: apperrors.ErrUpstreamNotFound},
+			},
+			wantCalls: []string{"Muse - Unknown"},
+			wantStats: model.SongInfoCacheStats{MemoryHits: 1, NegativeHits: 1, Misses: 1, Entries: 1},
+		},
+		{
+			name: "not found expires after the negative ttl",
+			steps: []lookupStep{
+				{group: "Muse", song: "Unknown", wantErr: apperrors.ErrUpstreamNotFound},
+				{group: "Muse", song: "Unknown", wait: 50 * time.Millisecond, wantErr: apperrors.ErrUpstreamNotFound},
+			},
+			wantCalls: []string{"Muse - Unknown", "Muse - Unknown"},
+			wantStats: model.SongInfoCacheStats{Misses: 2, Entries: 1},
+		},
+		{
+			name: "found outlives the negative ttl",
+			steps: []lookupStep{
+				{group: "Muse", song: "Uprising"},
+				{group: "Muse", song: "Uprising", wait: 50 * time.Millisecond},
+			},
+			wantCalls: []string{"Muse - Uprising"},
+			wantStats: model.SongInfoCacheStats{MemoryHits: 1, Misses: 1, Entries: 1},
+		},
+		{
+			name: "degraded details expire after the negative ttl",
+			steps: []lookupStep{
+				{group: "Muse", song: "partial"},
+				{group: "Muse", song: "partial"},
+				{group: "Muse", song: "partial", wait: 50 * time.Millisecond},
+			},
+			wantCalls: []string{"Muse - partial", "Muse - partial"},
+			wantStats: model.SongInfoCacheStats{MemoryHits: 1, Misses: 2, Entries: 1},
+		},
+		{
+			name: "failures are not cached",
+			steps: []lookupStep{
+				{group: "Muse", song: "down", wantErr: apperrors.ErrUpstreamUnavailable},
+				{group: "Muse", song: "down", wantErr: apperrors.ErrUpstreamUnavailable},
+			},
+			wantCalls: []string{"Muse - down", "Muse - down"},
+			wantStats: model.SongInfoCacheStats{Misses: 2},
+		},
+		{
+			name: "bypass skips the cache but stores the result",
+			steps: []lookupStep{
+				{group: "Muse", song: "Uprising"},
+				{group: "Muse", song: "Uprising", bypass: true},
+				{group: "Muse", song: "Uprising"},
+			},
+			wantCalls: []string{"Muse - Uprising", "Muse - Uprising"},
+			wantStats: model.SongInfoCacheStats{MemoryHits: 1, Misses: 1, Bypassed: 1, Entries: 1},
+		},
+	}
+
+	lgr := &logger.Logger{
+		InfoLogger:  log.New(io.Discard, "", 0),
+		DebugLogger: log.New(io.Discard, "", 0),
+		ErrorLogger: log.New(io.Discard, "", 0),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &fakeMusicInfoClient{details: map[string]model.SongDetail{"muse - uprising": uprising, "muse - partial": uprising}}
+			conf := config.SongInfoCacheConfig{INFO_CACHE_SIZE: 10, INFO_CACHE_TTL_MINUTES: 60}
+			cc := NewCachedMusicInfoClient(next, nil, conf, lgr).(*cachedMusicInfoClient)
+			cc.negativeTTL = 20 * time.Millisecond
+
+			for i, step := range tt.steps {
+				time.Sleep(step.wait)
+				ctx := context.Background()
+				if step.bypass {
+					ctx = WithoutCache(ctx)
+				}
+				detail, err := cc.GetSongDetail(ctx, step.group, step.song)
+				if step.wantErr != nil {
+					if !errors.Is(err, step.wantErr) {
+						t.Fatalf("step %d: error = %v, want %v", i, err, step.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %d: unexpected error: %v", i, err)
+				}
+				if detail.Text != uprising.Text || detail.Link != uprising.Link {
+					t.Fatalf("step %d: detail = %+v, want %+v", i, *detail, uprising)
+				}
+			}
+			if strings.Join(next.calls, ", ") != strings.Join(tt.wantCalls, ", ") {
+				t.Errorf("upstream calls = %q, want %q", next.calls, tt.wantCalls)
+			}
+			if got := cc.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker opens after threshold consecutive failures and rejects calls
+// until cooldown has passed. Then a single trial call is let through: its
+// success closes the breaker, its failure opens it again.
+//
+// Every call holds the ticket allow gave it. Calls admitted before the breaker
+// opened carry an old generation and cannot close or reopen it when they end
+// late; only the trial call decides the half-open state.
+type circuitBreaker struct {
+	mu         sync.Mutex
+	threshold  int
+	cooldown   time.Duration
+	now        func() time.Time
+	failures   int
+	openUntil  time.Time
+	generation uint64
+	trial      bool
+}
+
+type breakerTicket struct {
+	generation uint64
+	trial      bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+func (cb *circuitBreaker) allow() (breakerTicket, bool) {
+	if cb.threshold < 1 {
+		return breakerTicket{}, true
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.failures < cb.threshold {
+		return breakerTicket{generation: cb.generation}, true
+	}
+	if cb.trial || cb.now().Before(cb.openUntil) {
+		return breakerTicket{}, false
+	}
+	cb.trial = true
+	return breakerTicket{generation: cb.generation, trial: true}, true
+}
+
+func (cb *circuitBreaker) success(ticket breakerTicket) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if !cb.current(ticket) {
+		return
+	}
+	if ticket.trial {
+		cb.trial = false
+		cb.generation++
+	}
+	cb.failures = 0
+}
+
+// release ends a call that neither proves nor disproves the service is up.
+func (cb *circuitBreaker) release(ticket breakerTicket) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if ticket.trial && cb.current(ticket) {
+		cb.trial = false
+	}
+}
+
+// failure records a failed call and reports whether it opened the breaker.
+func (cb *circuitBreaker) failure(ticket breakerTicket) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.threshold < 1 || !cb.current(ticket) {
+		return false
+	}
+	if ticket.trial {
+		cb.trial = false
+	} else {
+		cb.failures++
+		if cb.failures < cb.threshold {
+			return false
+		}
+	}
+	cb.openUntil = cb.now().Add(cb.cooldown)
+	cb.generation++
+	return true
+}
+
+// current tells whether the call started after the last time the breaker
+// opened or closed. A trial only counts while it is still pending.
+func (cb *circuitBreaker) current(ticket breakerTicket) bool {
+	return ticket.generation == cb.generation && (!ticket.trial || cb.trial)
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+type breakerStep struct {
+	op   string // allow, success, release, failure or wait
+	call int
+	wait time.Duration
+	want bool // allow: the call is admitted, failure: the breaker opened
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	const cooldown = time.Minute
+	tests := []struct {
+		name      string
+		threshold int
+		steps     []breakerStep
+	}{
+		{
+			name:      "disabled",
+			threshold: 0,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "failure", call: 0, want: false},
+				{op: "allow", call: 1, want: true},
+				{op: "failure", call: 1, want: false},
+				{op: "allow", call: 2, want: true},
+			},
+		},
+		{
+			name:      "opens after threshold failures",
+			threshold: 2,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "allow", call: 1, want: true},
+				{op: "failure", call: 0, want: false},
+				{op: "failure", call: 1, want: true},
+				{op: "allow", call: 2, want: false},
+				{op: "wait", wait: cooldown - time.Second},
+				{op: "allow", call: 2, want: false},
+			},
+		},
+		{
+			name:      "success resets failures",
+			threshold: 2,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "failure", call: 0, want: false},
+				{op: "allow", call: 1, want: true},
+				{op: "success", call: 1},
+				{op: "allow", call: 2, want: true},
+				{op: "failure", call: 2, want: false},
+				{op: "allow", call: 3, want: true},
+			},
+		},
+		{
+			name:      "single trial after cooldown closes the breaker",
+			threshold: 1,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "failure", call: 0, want: true},
+				{op: "wait", wait: cooldown},
+				{op: "allow", call: 1, want: true},
+				{op: "allow", call: 2, want: false},
+				{op: "success", call: 1},
+				{op: "allow", call: 2, want: true},
+				{op: "allow", call: 3, want: true},
+			},
+		},
+		{
+			name:      "failed trial opens the breaker again",
+			threshold: 2,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "failure", call: 0, want: false},
+				{op: "allow", call: 1, want: true},
+				{op: "failure", call: 1, want: true},
+				{op: "wait", wait: cooldown},
+				{op: "allow", call: 2, want: true},
+				{op: "failure", call: 2, want: true},
+				{op: "allow", call: 3, want: false},
+				{op: "wait", wait: cooldown},
+				{op: "allow", call: 3, want: true},
+			},
+		},
+		{
+			name:      "released trial lets another one through",
+			threshold: 1,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "failure", call: 0, want: true},
+				{op: "wait", wait: cooldown},
+				{op: "allow", call: 1, want: true},
+				{op: "release", call: 1},
+				{op: "allow", call: 2, want: true},
+				{op: "allow", call: 3, want: false},
+			},
+		},
+		{
+			name:      "late success does not close an open breaker",
+			threshold: 1,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "allow", call: 1, want: true},
+				{op: "failure", call: 0, want: true},
+				{op: "success", call: 1},
+				{op: "allow", call: 2, want: false},
+			},
+		},
+		{
+			name:      "late calls do not end a pending trial",
+			threshold: 1,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "allow", call: 1, want: true},
+				{op: "allow", call: 2, want: true},
+				{op: "failure", call: 0, want: true},
+				{op: "wait", wait: cooldown},
+				{op: "allow", call: 3, want: true},
+				{op: "success", call: 1},
+				{op: "release", call: 2},
+				{op: "allow", call: 4, want: false},
+				{op: "failure", call: 3, want: true},
+				{op: "allow", call: 4, want: false},
+			},
+		},
+		{
+			name:      "late failure does not open a closed breaker",
+			threshold: 1,
+			steps: []breakerStep{
+				{op: "allow", call: 0, want: true},
+				{op: "allow", call: 1, want: true},
+				{op: "failure", call: 0, want: true},
+				{op: "wait", wait: cooldown},
+				{op: "allow", call: 2, want: true},
+				{op: "success", call: 2},
+				{op: "failure", call: 1, want: false},
+				{op: "allow", call: 3, want: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			cb := newCircuitBreaker(tt.threshold, cooldown)
+			cb.now = func() time.Time { return now }
+			tickets := map[int]breakerTicket{}
+
+			for i, step := range tt.steps {
+				switch step.op {
+				case "allow":
+					ticket, ok := cb.allow()
+					if ok != step.want {
+						t.Fatalf("step %d: allow() = %v, want %v", i, ok, step.want)
+					}
+					if ok {
+						tickets[step.call] = ticket
+					}
+				case "success":
+					cb.success(tickets[step.call])
+				case "release":
+					cb.release(tickets[step.call])
+				case "failure":
+					if opened := cb.failure(tickets[step.call]); opened != step.want {
+						t.Fatalf("step %d: failure() = %v, want %v", i, opened, step.want)
+					}
+				case "wait":
+					now = now.Add(step.wait)
+				default:
+					t.Fatalf("step %d: unknown op %q", i, step.op)
+				}
+			}
+		})
+	}
+}
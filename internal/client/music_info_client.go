@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+const maxRetryDelay = 10 * time.Second
+
+var (
+	ErrNotFound        = errors.New("song info not found")
+	ErrCircuitOpen     = errors.New("circuit breaker is open")
+	ErrInvalidResponse = errors.New("invalid song info response")
+)
+
+// StatusError is an unexpected HTTP status of the song info service.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("song info service responded with status %d", e.StatusCode)
+}
+
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+// songInfoResponse is the body of /info. The release date is parsed on its
+// own so that a malformed date is dropped instead of failing the response.
+type songInfoResponse struct {
+	ReleaseDate json.RawMessage `json:"releaseDate"`
+	Text        string          `json:"text"`
+	Link        string          `json:"link"`
+}
+
+// MusicInfoClient looks song details up in the external song info service.
+// Errors are apperrors of kind ErrUpstreamNotFound or ErrUpstreamUnavailable
+// that wrap ErrNotFound, ErrCircuitOpen, ErrInvalidResponse or *StatusError.
+type MusicInfoClient interface {
+	GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error)
+}
+
+type musicInfoClient struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	maxRetries int
+	retryBase  time.Duration
+	breaker    *circuitBreaker
+	lgr        *logger.Logger
+}
+
+func NewMusicInfoClient(conf config.MusicInfoConfig, lgr *logger.Logger) MusicInfoClient {
+	return &musicInfoClient{
+		baseURL:    strings.TrimRight(conf.EXTERNAL_API_URL, "/"),
+		httpClient: &http.Client{},
+		timeout:    time.Duration(conf.MUSIC_INFO_TIMEOUT_MS) * time.Millisecond,
+		maxRetries: conf.MUSIC_INFO_MAX_RETRIES,
+		retryBase:  time.Duration(conf.MUSIC_INFO_RETRY_BASE_MS) * time.Millisecond,
+		breaker:    newCircuitBreaker(conf.MUSIC_INFO_BREAKER_THRESHOLD, time.Duration(conf.MUSIC_INFO_BREAKER_COOLDOWN_SECONDS)*time.Second),
+		lgr:        lgr,
+	}
+}
+
+func (mc *musicInfoClient) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	ticket, ok := mc.breaker.allow()
+	if !ok {
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamUnavailable, "song info service is unavailable, try again later", ErrCircuitOpen)
+	}
+
+	songDetail, err := mc.getWithRetries(ctx, group, song)
+	switch {
+	case err == nil:
+		mc.breaker.success(ticket)
+		return songDetail, nil
+	case errors.Is(err, ErrNotFound):
+		mc.breaker.success(ticket)
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamNotFound, fmt.Sprintf("song info service has no data for %s - %s", group, song), err)
+	case ctx.Err() != nil:
+		mc.breaker.release(ticket)
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamUnavailable, "song info request was cancelled", err)
+	}
+
+	if mc.breaker.failure(ticket) {
+		mc.lgr.ErrorLogger.Printf("Song info service keeps failing, circuit breaker is open: %v\n", err)
+	}
+	if errors.Is(err, ErrInvalidResponse) {
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamUnavailable, "song info service returned an invalid response", err)
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return nil, apperrors.Wrap(apperrors.ErrUpstreamUnavailable, statusErr.Error(), err)
+	}
+	return nil, apperrors.Wrap(apperrors.ErrUpstreamUnavailable, "song info service is unavailable", err)
+}
+
+// getWithRetries retries transport errors, 5xx and 429 with exponential
+// backoff. A Retry-After header longer than the backoff is respected.
+func (mc *musicInfoClient) getWithRetries(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	for attempt := 0; ; attempt++ {
+		songDetail, err := mc.get(ctx, group, song)
+		if err == nil || attempt >= mc.maxRetries || !mc.retryable(ctx, err) {
+			return songDetail, err
+		}
+
+		delay := mc.retryDelay(attempt, err)
+		mc.lgr.DebugLogger.Printf("Song info request for %s - %s failed (%v), retrying in %s\n", group, song, err, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (mc *musicInfoClient) get(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	if mc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, mc.timeout)
+		defer cancel()
+	}
+
+	query := url.Values{}
+	query.Set("group", group)
+	query.Set("song", song)
+	apiUrl := mc.baseURL + "/info?" + query.Encode()
+	mc.lgr.DebugLogger.Printf("Calling external API: %s\n", apiUrl)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := mc.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mc.lgr.DebugLogger.Printf("External API response status: %d\n", resp.StatusCode)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, &StatusError{StatusCode: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	}
+
+	var body songInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	songDetail := model.SongDetail{Text: body.Text, Link: body.Link}
+	if len(body.ReleaseDate) > 0 {
+		if err := songDetail.ReleaseDate.UnmarshalJSON(body.ReleaseDate); err != nil {
+			mc.lgr.InfoLogger.Printf("Ignoring the release date of %s - %s from the song info service: %v\n", group, song, err)
+			songDetail.ReleaseDate = model.Date{}
+		}
+	}
+	return &songDetail, nil
+}
+
+// retryable tells transient failures. Errors caused by the caller's own
+// context are never retried, the per-request timeout is.
+func (mc *musicInfoClient) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.retryable()
+	}
+	return !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrInvalidResponse)
+}
+
+// retryDelay is the backoff for attempt, or the Retry-After of the failed
+// response when that is longer, capped at maxRetryDelay.
+func (mc *musicInfoClient) retryDelay(attempt int, err error) time.Duration {
+	delay := mc.backoff(attempt)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// backoff doubles the base delay with every attempt and adds jitter so that
+// concurrent callers do not retry in lockstep.
+func (mc *musicInfoClient) backoff(attempt int) time.Duration {
+	delay := mc.retryBase
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		attempt  int
+		min, max time.Duration
+	}{
+		{name: "no base delay", base: 0, attempt: 3, min: 0, max: 0},
+		{name: "first retry", base: 100 * time.Millisecond, attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "second retry", base: 100 * time.Millisecond, attempt: 1, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{name: "fourth retry", base: 100 * time.Millisecond, attempt: 3, min: 400 * time.Millisecond, max: 800 * time.Millisecond},
+		{name: "stops doubling past the cap", base: 8 * time.Second, attempt: 10, min: 8 * time.Second, max: 16 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := &musicInfoClient{retryBase: tt.base}
+			for i := 0; i < 100; i++ {
+				if got := mc.backoff(tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("backoff(%d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		err      error
+		min, max time.Duration
+	}{
+		{name: "transport error", base: time.Second, err: errors.New("connection refused"), min: 500 * time.Millisecond, max: time.Second},
+		{name: "no Retry-After", base: time.Second, err: &StatusError{StatusCode: http.StatusBadGateway}, min: 500 * time.Millisecond, max: time.Second},
+		{name: "Retry-After longer than backoff", base: time.Second, err: &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second}, min: 5 * time.Second, max: 5 * time.Second},
+		{name: "Retry-After shorter than backoff", base: 4 * time.Second, err: &StatusError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Second}, min: 2 * time.Second, max: 4 * time.Second},
+		{name: "Retry-After is capped", base: time.Second, err: &StatusError{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Hour}, min: maxRetryDelay, max: maxRetryDelay},
+		{name: "backoff is capped", base: 30 * time.Second, err: errors.New("timeout"), min: maxRetryDelay, max: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := &musicInfoClient{retryBase: tt.base}
+			for i := 0; i < 100; i++ {
+				if got := mc.retryDelay(0, tt.err); got < tt.min || got > tt.max {
+					t.Fatalf("retryDelay() = %s, want between %s and %s", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "missing", value: "", want: 0},
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "zero seconds", value: "0", want: 0},
+		{name: "negative seconds", value: "-5", want: 0},
+		{name: "garbage", value: "soon", want: 0},
+		{name: "future date", value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{name: "past date", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// infoResponse is one scripted answer of the fake song info service.
+type infoResponse struct {
+	status     int
+	retryAfter string
+	body       string
+	delay      time.Duration
+}
+
+func TestMusicInfoClientGetSongDetail(t *testing.T) {
+	const found = `{"releaseDate": "16.07.2006", "text": "Ooh baby, don't you know I suffer?", "link": "https://example.com/song"}`
+	tests := []struct {
+		name        string
+		group, song string
+		timeoutMs   int
+		responses   []infoResponse
+		wantCalls   int
+		wantErr     error
+		minElapsed  time.Duration
+	}{
+		{
+			name:      "slash in the group",
+			group:     "AC/DC",
+			song:      "Highway to Hell",
+			responses: []infoResponse{{status: http.StatusOK, body: found}},
+			wantCalls: 1,
+		},
+		{
+			name:      "apostrophes",
+			group:     "Guns N' Roses",
+			song:      "Sweet Child O' Mine",
+			responses: []infoResponse{{status: http.StatusOK, body: found}},
+			wantCalls: 1,
+		},
+		{
+			name:      "ampersand, plus and percent",
+			group:     "Simon & Garfunkel",
+			song:      "50% + 50% = 100%?#",
+			responses: []infoResponse{{status: http.StatusOK, body: found}},
+			wantCalls: 1,
+		},
+		{
+			name:      "not found",
+			group:     "Muse",
+			song:      "Unknown",
+			responses: []infoResponse{{status: http.StatusNotFound}},
+			wantCalls: 1,
+			wantErr:   apperrors.ErrUpstreamNotFound,
+		},
+		{
+			name:      "server errors are retried",
+			group:     "Muse",
+			song:      "Uprising",
+			responses: []infoResponse{{status: http.StatusBadGateway}, {status: http.StatusServiceUnavailable}, {status: http.StatusOK, body: found}},
+			wantCalls: 3,
+		},
+		{
+			name:       "too many requests waits for Retry-After",
+			group:      "Muse",
+			song:       "Uprising",
+			responses:  []infoResponse{{status: http.StatusTooManyRequests, retryAfter: "1"}, {status: http.StatusOK, body: found}},
+			wantCalls:  2,
+			minElapsed: time.Second,
+		},
+		{
+			name:      "retries run out",
+			group:     "Muse",
+			song:      "Uprising",
+			responses: []infoResponse{{status: http.StatusInternalServerError}},
+			wantCalls: 4,
+			wantErr:   apperrors.ErrUpstreamUnavailable,
+		},
+		{
+			name:      "client errors are not retried",
+			group:     "Muse",
+			song:      "Uprising",
+			responses: []infoResponse{{status: http.StatusBadRequest}},
+			wantCalls: 1,
+			wantErr:   apperrors.ErrUpstreamUnavailable,
+		},
+		{
+			name:      "invalid body is not retried",
+			group:     "Muse",
+			song:      "Uprising",
+			responses: []infoResponse{{status: http.StatusOK, body: "<html>"}},
+			wantCalls: 1,
+			wantErr:   ErrInvalidResponse,
+		},
+		{
+			name:      "slow responses time out and are retried",
+			group:     "Muse",
+			song:      "Uprising",
+			timeoutMs: 50,
+			responses: []infoResponse{{status: http.StatusOK, body: found, delay: time.Second}, {status: http.StatusOK, body: found}},
+			wantCalls: 2,
+		},
+	}
+
+	lgr := &logger.Logger{
+		InfoLogger:  log.New(io.Discard, "", 0),
+		DebugLogger: log.New(io.Discard, "", 0),
+		ErrorLogger: log.New(io.Discard, "", 0),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				response := tt.responses[min(calls, len(tt.responses)-1)]
+				calls++
+				mu.Unlock()
+
+				if r.URL.Path != "/info" || r.URL.Query().Get("group") != tt.group || r.URL.Query().Get("song") != tt.song {
+					t.Errorf("request %s, want /info with group=%q and song=%q", r.URL, tt.group, tt.song)
+				}
+				if response.delay > 0 {
+					select {
+					case <-time.After(response.delay):
+					case <-r.Context().Done():
+						return
+					}
+				}
+				if response.retryAfter != "" {
+					w.Header().Set("Retry-After", response.retryAfter)
+				}
+				w.WriteHeader(response.status)
+				io.WriteString(w, response.body)
+			}))
+			defer server.Close()
+
+			mc := NewMusicInfoClient(config.MusicInfoConfig{
+				EXTERNAL_API_URL:         server.URL + "/",
+				MUSIC_INFO_TIMEOUT_MS:    tt.timeoutMs,
+				MUSIC_INFO_MAX_RETRIES:   3,
+				MUSIC_INFO_RETRY_BASE_MS: 1,
+			}, lgr)
+			started := time.Now()
+			detail, err := mc.GetSongDetail(context.Background(), tt.group, tt.song)
+			elapsed := time.Since(started)
+
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("took %s, want at least %s", elapsed, tt.minElapsed)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if detail.ReleaseDate.String() != "2006-07-16" || detail.Link != "https://example.com/song" {
+				t.Errorf("detail = %+v", *detail)
+			}
+		})
+	}
+}
@@ -17,9 +17,67 @@ type DBConfig struct {
 	DB_PASSWORD string
 	DB_NAME     string
 }
+
+type TrashConfig struct {
+	TRASH_RETENTION_HOURS        int
+	TRASH_PURGE_INTERVAL_MINUTES int
+}
+type IdempotencyConfig struct {
+	IDEMPOTENCY_KEY_TTL_HOURS          int
+	IDEMPOTENCY_LEASE_SECONDS          int
+	IDEMPOTENCY_PURGE_INTERVAL_MINUTES int
+}
+type BatchConfig struct {
+	BATCH_WORKERS  int
+	BATCH_MAX_SIZE int
+}
+type JobConfig struct {
+	JOB_WORKERS               int
+	JOB_MAX_ATTEMPTS          int
+	JOB_POLL_INTERVAL_SECONDS int
+	JOB_RETRY_BASE_SECONDS    int
+	JOB_LEASE_SECONDS         int
+}
+type MusicInfoConfig struct {
+	EXTERNAL_API_URL                    string
+	MUSIC_INFO_TIMEOUT_MS               int
+	MUSIC_INFO_MAX_RETRIES              int
+	MUSIC_INFO_RETRY_BASE_MS            int
+	MUSIC_INFO_BREAKER_THRESHOLD        int
+	MUSIC_INFO_BREAKER_COOLDOWN_SECONDS int
+}
+type SongInfoCacheConfig struct {
+	INFO_CACHE_SIZE                   int
+	INFO_CACHE_TTL_MINUTES            int
+	INFO_CACHE_NEGATIVE_TTL_MINUTES   int
+	INFO_CACHE_PERSISTENT             bool
+	INFO_CACHE_PURGE_INTERVAL_MINUTES int
+}
+type MetadataConfig struct {
+	METADATA_PROVIDERS_FILE string
+}
+
+// MockInfoConfig configures cmd/mockinfo, the local stand-in for the song
+// info service. Rates are probabilities from 0 to 1.
+type MockInfoConfig struct {
+	MOCKINFO_PORT              int
+	MOCKINFO_FIXTURES_FILE     string
+	MOCKINFO_LATENCY_MS        int
+	MOCKINFO_LATENCY_JITTER_MS int
+	MOCKINFO_ERROR_RATE        float64
+	MOCKINFO_NOT_FOUND_RATE    float64
+	MOCKINFO_SEED              int
+}
 type Config struct {
-	API APIConfig
-	DB  DBConfig
+	API         APIConfig
+	DB          DBConfig
+	Trash       TrashConfig
+	Idempotency IdempotencyConfig
+	Batch       BatchConfig
+	Job         JobConfig
+	MusicInfo   MusicInfoConfig
+	InfoCache   SongInfoCacheConfig
+	Metadata    MetadataConfig
 }
 
 func NewConfig() *Config {
@@ -36,10 +94,60 @@ func NewConfig() *Config {
 			DB_PASSWORD: getEnv("DB_PASSWORD", ""),
 			DB_NAME:     getEnv("DB_NAME", ""),
 		},
+		Trash: TrashConfig{
+			TRASH_RETENTION_HOURS:        getEnvAsInt("TRASH_RETENTION_HOURS", 720),
+			TRASH_PURGE_INTERVAL_MINUTES: getEnvAsInt("TRASH_PURGE_INTERVAL_MINUTES", 60),
+		},
+		Idempotency: IdempotencyConfig{
+			IDEMPOTENCY_KEY_TTL_HOURS:          getEnvAsInt("IDEMPOTENCY_KEY_TTL_HOURS", 24),
+			IDEMPOTENCY_LEASE_SECONDS:          getEnvAsPositiveInt("IDEMPOTENCY_LEASE_SECONDS", 120),
+			IDEMPOTENCY_PURGE_INTERVAL_MINUTES: getEnvAsInt("IDEMPOTENCY_PURGE_INTERVAL_MINUTES", 60),
+		},
+		Batch: BatchConfig{
+			BATCH_WORKERS:  getEnvAsInt("BATCH_WORKERS", 8),
+			BATCH_MAX_SIZE: getEnvAsInt("BATCH_MAX_SIZE", 500),
+		},
+		Job: JobConfig{
+			JOB_WORKERS:               getEnvAsInt("JOB_WORKERS", 4),
+			JOB_MAX_ATTEMPTS:          getEnvAsInt("JOB_MAX_ATTEMPTS", 5),
+			JOB_POLL_INTERVAL_SECONDS: getEnvAsPositiveInt("JOB_POLL_INTERVAL_SECONDS", 2),
+			JOB_RETRY_BASE_SECONDS:    getEnvAsInt("JOB_RETRY_BASE_SECONDS", 10),
+			JOB_LEASE_SECONDS:         getEnvAsPositiveInt("JOB_LEASE_SECONDS", 300),
+		},
+		MusicInfo: MusicInfoConfig{
+			EXTERNAL_API_URL:                    getEnv("EXTERNAL_API_URL", ""),
+			MUSIC_INFO_TIMEOUT_MS:               getEnvAsInt("MUSIC_INFO_TIMEOUT_MS", 5000),
+			MUSIC_INFO_MAX_RETRIES:              getEnvAsInt("MUSIC_INFO_MAX_RETRIES", 3),
+			MUSIC_INFO_RETRY_BASE_MS:            getEnvAsInt("MUSIC_INFO_RETRY_BASE_MS", 200),
+			MUSIC_INFO_BREAKER_THRESHOLD:        getEnvAsInt("MUSIC_INFO_BREAKER_THRESHOLD", 5),
+			MUSIC_INFO_BREAKER_COOLDOWN_SECONDS: getEnvAsInt("MUSIC_INFO_BREAKER_COOLDOWN_SECONDS", 30),
+		},
+		InfoCache: SongInfoCacheConfig{
+			INFO_CACHE_SIZE:                   getEnvAsInt("INFO_CACHE_SIZE", 1000),
+			INFO_CACHE_TTL_MINUTES:            getEnvAsInt("INFO_CACHE_TTL_MINUTES", 1440),
+			INFO_CACHE_NEGATIVE_TTL_MINUTES:   getEnvAsInt("INFO_CACHE_NEGATIVE_TTL_MINUTES", 10),
+			INFO_CACHE_PERSISTENT:             getEnvAsBool("INFO_CACHE_PERSISTENT", false),
+			INFO_CACHE_PURGE_INTERVAL_MINUTES: getEnvAsInt("INFO_CACHE_PURGE_INTERVAL_MINUTES", 60),
+		},
+		Metadata: MetadataConfig{
+			METADATA_PROVIDERS_FILE: getEnv("METADATA_PROVIDERS_FILE", ""),
+		},
 	}
 
 }
 
+func NewMockInfoConfig() *MockInfoConfig {
+	return &MockInfoConfig{
+		MOCKINFO_PORT:              getEnvAsInt("MOCKINFO_PORT", 8081),
+		MOCKINFO_FIXTURES_FILE:     getEnv("MOCKINFO_FIXTURES_FILE", "config/mockinfo_fixtures.json"),
+		MOCKINFO_LATENCY_MS:        getEnvAsInt("MOCKINFO_LATENCY_MS", 0),
+		MOCKINFO_LATENCY_JITTER_MS: getEnvAsInt("MOCKINFO_LATENCY_JITTER_MS", 0),
+		MOCKINFO_ERROR_RATE:        getEnvAsFloat("MOCKINFO_ERROR_RATE", 0),
+		MOCKINFO_NOT_FOUND_RATE:    getEnvAsFloat("MOCKINFO_NOT_FOUND_RATE", 0),
+		MOCKINFO_SEED:              getEnvAsInt("MOCKINFO_SEED", 0),
+	}
+}
+
 func getEnv(key string, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -55,3 +163,30 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsPositiveInt is getEnvAsInt for settings where zero or less makes
+// no sense, e.g. intervals that would make a loop spin.
+func getEnvAsPositiveInt(key string, defaultValue int) int {
+	if value := getEnvAsInt(key, defaultValue); value > 0 {
+		return value
+	}
+	return defaultValue
+}
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if valueStr, exists := os.LookupEnv(key); exists {
+		if valueBool, err := strconv.ParseBool(valueStr); err == nil {
+			return valueBool
+		}
+		return defaultValue
+	}
+	return defaultValue
+}
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if valueStr, exists := os.LookupEnv(key); exists {
+		if valueFloat, err := strconv.ParseFloat(valueStr, 64); err == nil {
+			return valueFloat
+		}
+		return defaultValue
+	}
+	return defaultValue
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/audit"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+type JobController interface {
+	EnqueueSong(ctx context.Context, songRequest model.SongRequest, refreshExisting bool) (*model.SongJob, error)
+	GetJob(ctx context.Context, jobId int64) (*model.SongJob, error)
+	RetryJob(ctx context.Context, jobId int64) (*model.SongJob, error)
+}
+
+type jobController struct {
+	repo        repository.JobRepository
+	maxAttempts int
+	lgr         *logger.Logger
+}
+
+func NewJobController(repo repository.JobRepository, maxAttempts int, lgr *logger.Logger) JobController {
+	return &jobController{
+		repo:        repo,
+		maxAttempts: maxAttempts,
+		lgr:         lgr,
+	}
+}
+
+// EnqueueSong queues the insert for the job workers. The editor of the
+// request is stored with the job so the song history still names them.
+func (jc *jobController) EnqueueSong(ctx context.Context, songRequest model.SongRequest, refreshExisting bool) (*model.SongJob, error) {
+	jc.lgr.DebugLogger.Printf("EnqueueSong called with group: %s, song: %s\n", songRequest.Group, songRequest.Song)
+	if err := checkSongMode(songRequest, refreshExisting); err != nil {
+		return nil, err
+	}
+	maxAttempts := jc.maxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return jc.repo.EnqueueSongJob(ctx, model.SongJob{
+		Group:           songRequest.Group,
+		Song:            songRequest.Song,
+		Mode:            songRequest.Mode,
+		ReleaseDate:     songRequest.ReleaseDate,
+		Text:            songRequest.Text,
+		Link:            songRequest.Link,
+		RefreshExisting: refreshExisting,
+		Editor:          audit.Editor(ctx),
+		MaxAttempts:     maxAttempts,
+	})
+}
+
+func (jc *jobController) GetJob(ctx context.Context, jobId int64) (*model.SongJob, error) {
+	jc.lgr.DebugLogger.Printf("GetJob called with jobId: %d\n", jobId)
+	return jc.repo.GetSongJob(ctx, jobId)
+}
+
+func (jc *jobController) RetryJob(ctx context.Context, jobId int64) (*model.SongJob, error) {
+	jc.lgr.DebugLogger.Printf("RetryJob called with jobId: %d\n", jobId)
+	return jc.repo.RetrySongJob(ctx, jobId)
+}
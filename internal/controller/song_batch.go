@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
+)
+
+// InsertSongs enriches the songs from the song info service, as their mode
+// says, with a bounded number of concurrent requests and stores them. In
+// atomic mode all songs are stored in one transaction, so a single failure
+// stores nothing; otherwise every song is stored on its own. The response
+// has a result for each item.
+func (sc *songController) InsertSongs(ctx context.Context, songRequests []model.SongRequest, atomic bool) (*model.SongBatchResponse, error) {
+	if len(songRequests) == 0 {
+		return nil, apperrors.New(apperrors.ErrValidation, "batch must contain at least one song")
+	}
+	if sc.batch.BATCH_MAX_SIZE > 0 && len(songRequests) > sc.batch.BATCH_MAX_SIZE {
+		return nil, apperrors.New(apperrors.ErrValidation, fmt.Sprintf("batch must not contain more than %d songs", sc.batch.BATCH_MAX_SIZE))
+	}
+	sc.lgr.DebugLogger.Printf("InsertSongs called with %d songs, atomic: %t\n", len(songRequests), atomic)
+
+	results := make([]model.SongBatchResult, len(songRequests))
+	seen := make(map[string]int, len(songRequests))
+	pending := make([]int, 0, len(songRequests))
+	for i, songRequest := range songRequests {
+		results[i].Index = i
+		if strings.TrimSpace(songRequest.Group) == "" || strings.TrimSpace(songRequest.Song) == "" {
+			results[i] = sc.batchFailure(i, apperrors.New(apperrors.ErrValidation, "group and song are required"))
+			continue
+		}
+		if err := checkSongMode(songRequest, false); err != nil {
+			results[i] = sc.batchFailure(i, err)
+			continue
+		}
+		key := strings.ToLower(songRequest.Group) + "\x00" + strings.ToLower(songRequest.Song)
+		if first, ok := seen[key]; ok {
+			results[i] = sc.batchFailure(i, apperrors.New(apperrors.ErrConflict, fmt.Sprintf("duplicate of item %d of the batch", first)))
+			continue
+		}
+		seen[key] = i
+		pending = append(pending, i)
+	}
+
+	songs := sc.enrichSongs(ctx, songRequests, pending, results)
+
+	ready := make([]int, 0, len(pending))
+	for _, i := range pending {
+		if results[i].Status == "" {
+			ready = append(ready, i)
+		}
+	}
+	if atomic {
+		if err := sc.insertSongsAtomically(ctx, songs, ready, len(ready) < len(songRequests), results); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, i := range ready {
+			inserted, err := sc.repo.InsertSong(ctx, songs[i].song)
+			if err != nil {
+				results[i] = sc.batchFailure(i, err)
+				continue
+			}
+			results[i] = model.SongBatchResult{Index: i, Status: model.BatchCreated, SoundId: inserted.SoundId, Sources: &songs[i].sources}
+		}
+	}
+
+	response := &model.SongBatchResponse{Atomic: atomic, Results: results}
+	for _, result := range results {
+		if result.Status == model.BatchCreated {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+	sc.lgr.InfoLogger.Printf("Batch of %d songs: %d created, %d failed\n", len(songRequests), response.Created, response.Failed)
+	return response, nil
+}
+
+// enrichedSong is an item of a batch that is ready to be stored.
+type enrichedSong struct {
+	song    model.Song
+	sources model.SongSources
+}
+
+// enrichSongs fetches song details for the pending items using at most
+// BATCH_WORKERS concurrent requests. Songs that already exist are reported
+// as conflicts without asking the song info service.
+func (sc *songController) enrichSongs(ctx context.Context, songRequests []model.SongRequest, pending []int, results []model.SongBatchResult) []enrichedSong {
+	songs := make([]enrichedSong, len(songRequests))
+	workers := sc.batch.BATCH_WORKERS
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				song, err := sc.enrichSong(ctx, songRequests[i])
+				if err != nil {
+					results[i] = sc.batchFailure(i, err)
+					continue
+				}
+				songs[i] = *song
+			}
+		}()
+	}
+	for _, i := range pending {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return songs
+}
+
+func (sc *songController) enrichSong(ctx context.Context, songRequest model.SongRequest) (*enrichedSong, error) {
+	existing, err := sc.repo.FindSong(ctx, songRequest.Group, songRequest.Song)
+	if err == nil {
+		return nil, repository.SongExistsError(existing)
+	}
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, err
+	}
+	songDetail, err := sc.getSongDetail(ctx, songRequest)
+	if err != nil {
+		return nil, err
+	}
+	song, sources := model.NewSong(songRequest, songDetail)
+	return &enrichedSong{song: song, sources: sources}, nil
+}
+
+// insertSongsAtomically stores the ready items in one transaction. Nothing is
+// stored when another item of the batch has already failed.
+func (sc *songController) insertSongsAtomically(ctx context.Context, songs []enrichedSong, ready []int, failed bool, results []model.SongBatchResult) error {
+	abort := func(reason string) {
+		for _, i := range ready {
+			if results[i].Status == "" {
+				results[i] = model.SongBatchResult{Index: i, Status: model.BatchAborted, Error: reason}
+			}
+		}
+	}
+	if failed {
+		abort("not stored because another item of the batch failed")
+		return nil
+	}
+
+	batch := make([]model.Song, 0, len(ready))
+	for _, i := range ready {
+		batch = append(batch, songs[i].song)
+	}
+	inserted, err := sc.repo.InsertSongs(ctx, batch)
+	var batchErr *repository.BatchInsertError
+	if errors.As(err, &batchErr) {
+		failedIndex := ready[batchErr.Index]
+		results[failedIndex] = sc.batchFailure(failedIndex, batchErr.Err)
+		abort(fmt.Sprintf("not stored because item %d of the batch failed", failedIndex))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Batch insert method: %w", err)
+	}
+	for n, i := range ready {
+		results[i] = model.SongBatchResult{Index: i, Status: model.BatchCreated, SoundId: inserted[n].SoundId, Sources: &songs[i].sources}
+	}
+	return nil
+}
+
+// batchFailure turns an error into a per-item result. Only messages meant for
+// clients are copied, anything else is reported as an unexpected error.
+func (sc *songController) batchFailure(index int, err error) model.SongBatchResult {
+	sc.lgr.DebugLogger.Printf("Item %d of the batch failed: %v\n", index, err)
+	result := model.SongBatchResult{Index: index, Status: model.BatchError, Error: "unexpected error"}
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		result.Error = appErr.Message
+	}
+	switch {
+	case errors.Is(err, apperrors.ErrConflict):
+		result.Status = model.BatchConflict
+		if appErr != nil {
+			result.SoundId, _ = appErr.Extensions["sound_id"].(int)
+		}
+	case errors.Is(err, apperrors.ErrUpstreamNotFound), errors.Is(err, apperrors.ErrUpstreamUnavailable):
+		result.Status = model.BatchUpstreamError
+	case errors.Is(err, apperrors.ErrValidation):
+		result.Status = model.BatchInvalid
+	}
+	return result
+}
@@ -2,97 +2,163 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
 	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
-	"net/http"
-        "sort"
-	"os"
 	"strings"
 )
 
 type SongController interface {
-	GetSongs(ctx context.Context, sortParam string, page int, pageSize int) ([]model.Song, error)
+	GetSongs(ctx context.Context, filter model.SongFilter, sortParam string, page int, pageSize int) ([]model.Song, int, error)
+	GetSongsByCursor(ctx context.Context, filter model.SongFilter, sortParam string, cursor string, limit int) (*model.SongCursorPage, error)
+	SearchSongs(ctx context.Context, query string, page int, pageSize int) ([]model.SongSearchResult, int, error)
+	SuggestSongs(ctx context.Context, query string, limit int) ([]model.SongSuggestion, error)
 	GetSong(ctx context.Context, songId int) (*model.Song, error)
-	GetSongText(ctx context.Context, songId int, pageSize int, page int) ([]string, error)
-	InsertSong(ctx context.Context, songRequest model.SongRequest) error
-	UpdateSong(ctx context.Context, songId int, song model.Song) error
-	DeleteSong(ctx context.Context, songId int) error
+	GetSongText(ctx context.Context, songId int, pageSize int, page int) ([]string, int, error)
+	InsertSong(ctx context.Context, songRequest model.SongRequest, refreshExisting bool) (*model.SongInsertResult, bool, error)
+	InsertSongs(ctx context.Context, songRequests []model.SongRequest, atomic bool) (*model.SongBatchResponse, error)
+	UpdateSong(ctx context.Context, songId int, song model.SongPatch, ifMatch *model.IfMatch) (*model.Song, error)
+	PatchSong(ctx context.Context, songId int, patch model.SongPatch, ifMatch *model.IfMatch) (*model.Song, error)
+	DeleteSong(ctx context.Context, songId int, ifMatch *model.IfMatch) error
+	GetTrash(ctx context.Context, page int, pageSize int) ([]model.Song, int, error)
+	RestoreSong(ctx context.Context, songId int) (*model.Song, error)
+	GetSongRevisions(ctx context.Context, songId int, page int, pageSize int) ([]model.SongRevision, int, error)
+	DiffSongRevision(ctx context.Context, songId int, revision int, against int) (*model.SongRevisionDiff, error)
+	RevertSong(ctx context.Context, songId int, revision int, ifMatch *model.IfMatch) (*model.Song, error)
+	RefreshSong(ctx context.Context, songId int, dryRun bool, ifMatch *model.IfMatch) (*model.SongRefresh, error)
 }
 
+const (
+	maxSuggestions   = 50
+	similarThreshold = 0.6
+	maxSimilarSongs  = 5
+)
+
 type songController struct {
-	repo repository.SongRepository
-	lgr  *logger.Logger
+	repo       repository.SongRepository
+	infoClient client.MusicInfoClient
+	batch      config.BatchConfig
+	lgr        *logger.Logger
 }
 
-func NewSongController(repo repository.SongRepository, lgr *logger.Logger) SongController {
+func NewSongController(repo repository.SongRepository, infoClient client.MusicInfoClient, batch config.BatchConfig, lgr *logger.Logger) SongController {
 	return &songController{
-		repo: repo,
-		lgr:  lgr,
+		repo:       repo,
+		infoClient: infoClient,
+		batch:      batch,
+		lgr:        lgr,
 	}
 }
 
-func (sc *songController) GetSongs(ctx context.Context, sortParam string, page int, pageSize int) ([]model.Song, error) {
-	songs, err := sc.repo.GetSongs()
+var allowedSorts = map[string]bool{
+	"sound_id":     true,
+	"text_length":  true,
+	"song":         true,
+	"release_date": true,
+}
+
+func (sc *songController) GetSongs(ctx context.Context, filter model.SongFilter, sortParam string, page int, pageSize int) ([]model.Song, int, error) {
+	if !allowedSorts[sortParam] {
+		sortParam = "sound_id"
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	sc.lgr.DebugLogger.Printf("Getting songs sorted by %s, page: %d, pageSize: %d\n", sortParam, page, pageSize)
+
+	songs, total, err := sc.repo.GetSongs(ctx, model.SongQuery{
+		Filter: filter,
+		Sort:   sortParam,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve songs: %v", err)
+		return nil, 0, fmt.Errorf("failed to retrieve songs: %w", err)
 	}
-	allowedSorts := map[string]bool{
-		"sound_id":     true,
-		"text_length":  true,
-		"song":         true,
-		"release_date": true,
+	return songs, total, nil
+}
+
+func (sc *songController) GetSongsByCursor(ctx context.Context, filter model.SongFilter, sortParam string, cursor string, limit int) (*model.SongCursorPage, error) {
+	query := model.SongCursorQuery{Filter: filter, Sort: sortParam, Limit: limit}
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil || !allowedSorts[after.Sort] {
+			return nil, apperrors.New(apperrors.ErrValidation, "invalid cursor")
+		}
+		if sortParam != "" && sortParam != after.Sort {
+			return nil, apperrors.New(apperrors.ErrValidation, fmt.Sprintf("cursor was issued for sort %s", after.Sort))
+		}
+		query.Sort = after.Sort
+		query.After = after
 	}
-	if !allowedSorts[sortParam] {
-		sortParam = "sound_id"
+	if !allowedSorts[query.Sort] {
+		query.Sort = "sound_id"
 	}
-	sc.lgr.DebugLogger.Printf("Sortiвng songs by %s\n", sortParam)
-	switch sortParam {
-	case "sound_id":
-		sort.Slice(songs, func(i, j int) bool {
-			return songs[i].SoundId < songs[j].SoundId
-		})
-	case "text_length":
-		sort.Slice(songs, func(i, j int) bool {
-			return len(songs[i].Text) < len(songs[j].Text)
-		})
-	case "song":
-		sort.Slice(songs, func(i, j int) bool {
-			return strings.ToLower(songs[i].Song) < strings.ToLower(songs[j].Song)
-		})
-	case "release_date":
-		sort.Slice(songs, func(i, j int) bool {
-			dateI, errI := time.Parse("02.01.2006", songs[i].ReleaseDate)
-			dateJ, errJ := time.Parse("02.01.2006", songs[j].ReleaseDate)
-			if errI != nil || errJ != nil {
-				return false
-			}
-			return dateI.Before(dateJ)
-		})
-	}
-	sc.lgr.DebugLogger.Printf("Total songs after sorting: %d\n", len(songs))
-	totalSongs := len(songs)
-	start := (page - 1) * pageSize
-	if start < 0 {
-		start = 0
+	if query.Limit < 1 {
+		query.Limit = 1
 	}
-	if start >= totalSongs {
-		start = totalSongs
+	sc.lgr.DebugLogger.Printf("Getting songs sorted by %s with cursor, limit: %d\n", query.Sort, query.Limit)
+
+	page, err := sc.repo.GetSongsByCursor(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve songs: %w", err)
 	}
-	end := start + pageSize
-	if end > totalSongs {
-		end = totalSongs
+	return &model.SongCursorPage{
+		Items:      page.Songs,
+		NextCursor: encodeCursor(page.Next),
+		PrevCursor: encodeCursor(page.Prev),
+	}, nil
+}
+
+func (sc *songController) SearchSongs(ctx context.Context, query string, page int, pageSize int) ([]model.SongSearchResult, int, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, apperrors.New(apperrors.ErrValidation, "search query must not be empty")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 1
 	}
-	paginatedSongs := songs[start:end]
-	return paginatedSongs, nil
+	sc.lgr.DebugLogger.Printf("SearchSongs called with query: %q, page: %d, pageSize: %d\n", query, page, pageSize)
+
+	results, total, err := sc.repo.SearchSongs(ctx, query, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search songs: %w", err)
+	}
+	return results, total, nil
+}
+
+func (sc *songController) SuggestSongs(ctx context.Context, query string, limit int) ([]model.SongSuggestion, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, apperrors.New(apperrors.ErrValidation, "suggest query must not be empty")
+	}
+	if limit < 1 || limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+	sc.lgr.DebugLogger.Printf("SuggestSongs called with query: %q, limit: %d\n", query, limit)
+
+	suggestions, err := sc.repo.SuggestSongs(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest songs: %w", err)
+	}
+	return suggestions, nil
 }
 
 func (sc *songController) GetSong(ctx context.Context, songId int) (*model.Song, error) {
 	sc.lgr.DebugLogger.Printf("GetSong called with songId: %d\n", songId)
 
-	song, err := sc.repo.GetSong(songId)
+	song, err := sc.repo.GetSong(ctx, songId)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +168,7 @@ func (sc *songController) GetSong(ctx context.Context, songId int) (*model.Song,
 	return song, nil
 }
 
-func (sc *songController) GetSongText(ctx context.Context, songId int, pageSize int, page int) ([]string, error) {
+func (sc *songController) GetSongText(ctx context.Context, songId int, pageSize int, page int) ([]string, int, error) {
 	if pageSize < 1 {
 		pageSize = 1
 		sc.lgr.DebugLogger.Printf("Invalid page size, defaulting to %d\n", pageSize)
@@ -114,9 +180,9 @@ func (sc *songController) GetSongText(ctx context.Context, songId int, pageSize
 
 	sc.lgr.DebugLogger.Printf("GetSongText called with songId: %d, page: %d, pageSize: %d\n", songId, page, pageSize)
 
-	song, err := sc.repo.GetSong(songId)
+	song, err := sc.repo.GetSong(ctx, songId)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	text := song.Text
@@ -139,79 +205,180 @@ func (sc *songController) GetSongText(ctx context.Context, songId int, pageSize
 
 	sc.lgr.InfoLogger.Printf("Returning %d verses from page %d with page size %d\n", len(paginatedVerses), page, pageSize)
 
-	return paginatedVerses, nil
+	return paginatedVerses, totalVerses, nil
 }
 
-func (sc *songController) InsertSong(ctx context.Context, songRequest model.SongRequest) error {
+// InsertSong stores a new song and reports whether it was created. A song
+// with the same group and title is a conflict, unless refreshExisting is
+// set: then the existing song is updated from the song info service.
+func (sc *songController) InsertSong(ctx context.Context, songRequest model.SongRequest, refreshExisting bool) (*model.SongInsertResult, bool, error) {
+	if err := checkSongMode(songRequest, refreshExisting); err != nil {
+		return nil, false, err
+	}
 
-	apiUrl := os.Getenv("EXTERNAL_API_URL") + "/info?group=" + songRequest.Group + "&song=" + songRequest.Song
-	sc.lgr.DebugLogger.Printf("Calling external API: %s\n", apiUrl)
+	existing, err := sc.repo.FindSong(ctx, songRequest.Group, songRequest.Song)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return nil, false, fmt.Errorf("Insert method: %w", err)
+	}
+	if existing != nil {
+		if !refreshExisting {
+			return nil, false, repository.SongExistsError(existing)
+		}
+		refresh, err := sc.refreshSong(ctx, existing, false, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		return &model.SongInsertResult{Song: refresh.Song}, false, nil
+	}
 
-	resp, err := http.Get(apiUrl)
+	songDetail, err := sc.getSongDetail(ctx, songRequest)
 	if err != nil {
-		return fmt.Errorf("External api error:%s, status:%v", err, http.StatusInternalServerError)
+		return nil, false, err
 	}
-	defer resp.Body.Close()
+	song, sources := model.NewSong(songRequest, songDetail)
 
-	sc.lgr.DebugLogger.Printf("External API response status: %d\n", resp.StatusCode)
+	similar := sc.findSimilarSongs(ctx, songRequest)
 
-	if resp.StatusCode == http.StatusOK {
-		var songDetail model.SongDetail
-		err = json.NewDecoder(resp.Body).Decode(&songDetail)
-		if err != nil {
-			return fmt.Errorf("External api error:%s, status:%v", err, http.StatusInternalServerError)
-		}
+	inserted, err := sc.repo.InsertSong(ctx, song)
+	if err != nil {
+		return nil, false, fmt.Errorf("Insert method: %w", err)
+	}
 
-		sc.lgr.DebugLogger.Printf("Successfully decoded song detail from API response\n")
+	return &model.SongInsertResult{Song: *inserted, Sources: &sources, Similar: similar}, true, nil
+}
 
-		song := model.NewSong(songRequest, songDetail)
-		if err := sc.repo.InsertSong(song); err != nil {
-			return fmt.Errorf("Insert method: %s", err)
+// getSongDetail asks the song info service unless the mode says otherwise.
+// It returns nil when the details of the request are to be used instead.
+func (sc *songController) getSongDetail(ctx context.Context, songRequest model.SongRequest) (*model.SongDetail, error) {
+	if songRequest.Mode == model.SongModeManual {
+		return nil, nil
+	}
+	songDetail, err := sc.infoClient.GetSongDetail(ctx, songRequest.Group, songRequest.Song)
+	if err != nil && songRequest.Mode == model.SongModeEnrichOrFallback &&
+		(errors.Is(err, apperrors.ErrUpstreamNotFound) || errors.Is(err, apperrors.ErrUpstreamUnavailable)) {
+		sc.lgr.InfoLogger.Printf("Using the supplied details for %s - %s: %v\n", songRequest.Group, songRequest.Song, err)
+		return nil, nil
+	}
+	return songDetail, err
+}
+
+// checkSongMode rejects unknown modes. Refreshing an existing song always
+// asks the song info service, which contradicts the manual mode.
+func checkSongMode(songRequest model.SongRequest, refreshExisting bool) error {
+	switch songRequest.Mode {
+	case "", model.SongModeEnrich, model.SongModeEnrichOrFallback:
+	case model.SongModeManual:
+		if refreshExisting {
+			return apperrors.New(apperrors.ErrValidation, "on_conflict=update refreshes the song from the song info service and cannot be used with mode manual")
 		}
+	default:
+		return apperrors.New(apperrors.ErrValidation, fmt.Sprintf("unknown mode %q, expected enrich, manual or enrich_or_fallback", songRequest.Mode))
+	}
+	return nil
+}
 
+// findSimilarSongs only produces warnings, so a failed lookup never blocks
+// the insert.
+func (sc *songController) findSimilarSongs(ctx context.Context, songRequest model.SongRequest) []model.SongSuggestion {
+	similar, err := sc.repo.FindSimilarSongs(ctx, songRequest.Group, songRequest.Song, similarThreshold, maxSimilarSongs)
+	if err != nil {
+		sc.lgr.ErrorLogger.Printf("Failed to look for songs similar to %s - %s: %v\n", songRequest.Group, songRequest.Song, err)
 		return nil
-	} else {
-		return fmt.Errorf("External api error: bad request, status:%v", resp.StatusCode)
 	}
+	for _, suggestion := range similar {
+		sc.lgr.InfoLogger.Printf("Song %s - %s is similar to existing song %d: %s - %s (score %.2f)\n",
+			songRequest.Group, songRequest.Song, suggestion.SoundId, suggestion.Group, suggestion.Song, suggestion.Score)
+	}
+	return similar
 }
 
-func (sc *songController) UpdateSong(ctx context.Context, songId int, song model.Song) error {
+func (sc *songController) UpdateSong(ctx context.Context, songId int, song model.SongPatch, ifMatch *model.IfMatch) (*model.Song, error) {
 	sc.lgr.DebugLogger.Printf("UpdateSong called with songId: %d, new song data: %+v\n", songId, song)
 
-	songLastVer, err := sc.repo.GetSong(songId)
-	if err != nil {
-		return err
+	if missing := song.MissingFields(); len(missing) > 0 {
+		return nil, apperrors.New(apperrors.ErrValidation, fmt.Sprintf("PUT replaces the whole song, missing fields: %s", strings.Join(missing, ", ")))
 	}
 
-	if song.Group == "" {
-		song.Group = songLastVer.Group
+	updated, err := sc.applySongPatch(ctx, songId, song, ifMatch)
+	if err != nil {
+		return nil, fmt.Errorf("Put method: %w", err)
 	}
-	if song.Song == "" {
-		song.Song = songLastVer.Song
+
+	return updated, nil
+}
+
+func (sc *songController) PatchSong(ctx context.Context, songId int, patch model.SongPatch, ifMatch *model.IfMatch) (*model.Song, error) {
+	sc.lgr.DebugLogger.Printf("PatchSong called with songId: %d, patch: %+v\n", songId, patch)
+
+	song, err := sc.applySongPatch(ctx, songId, patch, ifMatch)
+	if err != nil {
+		return nil, fmt.Errorf("Patch method: %w", err)
 	}
-	if song.ReleaseDate == "" {
-		song.ReleaseDate = songLastVer.ReleaseDate
+
+	return song, nil
+}
+
+// applySongPatch merges the patch into the stored song and writes it back
+// only if nobody changed the song in between. Without If-Match a concurrent
+// change is reported as a conflict instead of a failed precondition.
+func (sc *songController) applySongPatch(ctx context.Context, songId int, patch model.SongPatch, ifMatch *model.IfMatch) (*model.Song, error) {
+	songLastVer, err := sc.repo.GetSong(ctx, songId)
+	if err != nil {
+		return nil, err
 	}
-	if song.Text == "" {
-		song.Text = songLastVer.Text
+	if !ifMatch.Matches(songLastVer.Version) {
+		return nil, apperrors.New(apperrors.ErrPreconditionFailed, fmt.Sprintf("song with ID %d has version %d", songId, songLastVer.Version))
 	}
-	if song.Link == "" {
-		song.Link = songLastVer.Link
+
+	song, err := patch.Apply(*songLastVer)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrValidation, err.Error(), err)
 	}
 
-	if err := sc.repo.UpdateSong(songId, song); err != nil {
-		return fmt.Errorf("Put method: %s", err)
+	updated, err := sc.repo.UpdateSong(ctx, songId, song)
+	if errors.Is(err, apperrors.ErrPreconditionFailed) && ifMatch == nil {
+		return nil, apperrors.Wrap(apperrors.ErrConflict, fmt.Sprintf("song with ID %d was modified concurrently, retry the request", songId), err)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return updated, nil
 }
 
-func (sc *songController) DeleteSong(ctx context.Context, songId int) error {
+func (sc *songController) DeleteSong(ctx context.Context, songId int, ifMatch *model.IfMatch) error {
 	sc.lgr.DebugLogger.Printf("DeleteSong called with songId: %d\n", songId)
 
-	if err := sc.repo.DeleteSong(songId); err != nil {
-		return fmt.Errorf("Delete method: %s", err)
+	if err := sc.repo.DeleteSong(ctx, songId, ifMatch); err != nil {
+		return fmt.Errorf("Delete method: %w", err)
 	}
 
 	return nil
 }
+
+func (sc *songController) GetTrash(ctx context.Context, page int, pageSize int) ([]model.Song, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	sc.lgr.DebugLogger.Printf("GetTrash called with page: %d, pageSize: %d\n", page, pageSize)
+
+	songs, total, err := sc.repo.GetDeletedSongs(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve deleted songs: %w", err)
+	}
+	return songs, total, nil
+}
+
+func (sc *songController) RestoreSong(ctx context.Context, songId int) (*model.Song, error) {
+	sc.lgr.DebugLogger.Printf("RestoreSong called with songId: %d\n", songId)
+
+	song, err := sc.repo.RestoreSong(ctx, songId)
+	if err != nil {
+		return nil, fmt.Errorf("Restore method: %w", err)
+	}
+
+	return song, nil
+}
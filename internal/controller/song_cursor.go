@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+func encodeCursor(cursor *model.SongCursor) *string {
+	if cursor == nil {
+		return nil
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return nil
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return &encoded
+}
+
+func decodeCursor(encoded string) (*model.SongCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor model.SongCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+)
+
+// RefreshSong fetches fresh details of a stored song and applies the fields
+// that differ, except pinned ones. A dry run only reports the changes.
+func (sc *songController) RefreshSong(ctx context.Context, songId int, dryRun bool, ifMatch *model.IfMatch) (*model.SongRefresh, error) {
+	sc.lgr.DebugLogger.Printf("RefreshSong called with songId: %d, dryRun: %t\n", songId, dryRun)
+
+	current, err := sc.repo.GetSong(ctx, songId)
+	if err != nil {
+		return nil, err
+	}
+	if !ifMatch.Matches(current.Version) {
+		return nil, apperrors.New(apperrors.ErrPreconditionFailed, fmt.Sprintf("song with ID %d has version %d", songId, current.Version))
+	}
+
+	refresh, err := sc.refreshSong(ctx, current, dryRun, ifMatch)
+	if err != nil {
+		return nil, fmt.Errorf("Refresh method: %w", err)
+	}
+
+	return refresh, nil
+}
+
+// refreshSong always asks the song info service, bypassing the cache, and
+// writes the song only if an unpinned field changed. The write fails if the
+// song changed since it was read.
+func (sc *songController) refreshSong(ctx context.Context, existing *model.Song, dryRun bool, ifMatch *model.IfMatch) (*model.SongRefresh, error) {
+	sc.lgr.DebugLogger.Printf("Refreshing song with ID %d from the song info service\n", existing.SoundId)
+
+	songDetail, err := sc.infoClient.GetSongDetail(client.WithoutCache(ctx), existing.Group, existing.Song)
+	if err != nil {
+		return nil, err
+	}
+
+	song, changes := model.ApplySongDetail(*existing, *songDetail)
+	refresh := &model.SongRefresh{SoundId: existing.SoundId, DryRun: dryRun, Changes: changes, Song: song}
+	applicable := false
+	for _, change := range changes {
+		applicable = applicable || change.Applicable()
+	}
+	if dryRun || !applicable {
+		return refresh, nil
+	}
+
+	updated, err := sc.repo.UpdateSong(ctx, existing.SoundId, song)
+	if errors.Is(err, apperrors.ErrPreconditionFailed) && ifMatch == nil {
+		return nil, apperrors.Wrap(apperrors.ErrConflict, fmt.Sprintf("song with ID %d was modified concurrently, retry the request", existing.SoundId), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sc.lgr.InfoLogger.Printf("Refreshed song with ID %d, %d changed fields\n", existing.SoundId, len(changes))
+
+	refresh.Applied = true
+	refresh.Song = *updated
+	return refresh, nil
+}
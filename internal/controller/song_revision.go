@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/linediff"
+)
+
+func (sc *songController) GetSongRevisions(ctx context.Context, songId int, page int, pageSize int) ([]model.SongRevision, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	sc.lgr.DebugLogger.Printf("GetSongRevisions called with songId: %d, page: %d, pageSize: %d\n", songId, page, pageSize)
+
+	revisions, total, err := sc.repo.GetSongRevisions(ctx, songId, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve song revisions: %w", err)
+	}
+	return revisions, total, nil
+}
+
+// DiffSongRevision compares the lyrics of a revision with another one, by
+// default with the revision right before it. The first revision is
+// compared with empty lyrics.
+func (sc *songController) DiffSongRevision(ctx context.Context, songId int, revision int, against int) (*model.SongRevisionDiff, error) {
+	sc.lgr.DebugLogger.Printf("DiffSongRevision called with songId: %d, revision: %d, against: %d\n", songId, revision, against)
+
+	to, err := sc.repo.GetSongRevision(ctx, songId, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromText string
+	fromRevision := against
+	if against == 0 {
+		fromRevision = revision - 1
+	}
+	from, err := sc.repo.GetSongRevision(ctx, songId, fromRevision)
+	switch {
+	case err == nil:
+		fromText = from.Text
+	case errors.Is(err, apperrors.ErrNotFound) && against == 0:
+		fromRevision = 0
+	default:
+		return nil, err
+	}
+
+	return &model.SongRevisionDiff{
+		SoundId:      songId,
+		FromRevision: fromRevision,
+		ToRevision:   revision,
+		Lines:        linediff.Diff(fromText, to.Text),
+	}, nil
+}
+
+// RevertSong writes the content of an old revision back as a new revision.
+func (sc *songController) RevertSong(ctx context.Context, songId int, revision int, ifMatch *model.IfMatch) (*model.Song, error) {
+	sc.lgr.DebugLogger.Printf("RevertSong called with songId: %d, revision: %d\n", songId, revision)
+
+	current, err := sc.repo.GetSong(ctx, songId)
+	if err != nil {
+		return nil, err
+	}
+	if !ifMatch.Matches(current.Version) {
+		return nil, apperrors.New(apperrors.ErrPreconditionFailed, fmt.Sprintf("song with ID %d has version %d", songId, current.Version))
+	}
+
+	target, err := sc.repo.GetSongRevision(ctx, songId, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	song := *current
+	song.Group = target.Group
+	song.Song = target.Song
+	song.ReleaseDate = target.ReleaseDate
+	song.Text = target.Text
+	song.Link = target.Link
+
+	reverted, err := sc.repo.RevertSong(ctx, songId, song)
+	if errors.Is(err, apperrors.ErrPreconditionFailed) && ifMatch == nil {
+		return nil, apperrors.Wrap(apperrors.ErrConflict, fmt.Sprintf("song with ID %d was modified concurrently, retry the request", songId), err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Revert method: %w", err)
+	}
+
+	return reverted, nil
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CacheHandler interface {
+	GetSongInfoCacheStats(c *fiber.Ctx) error
+}
+
+type cacheHandler struct {
+	infoClient client.CachedMusicInfoClient
+	lgr        *logger.Logger
+}
+
+func NewCacheHandler(infoClient client.CachedMusicInfoClient, lgr *logger.Logger) CacheHandler {
+	return &cacheHandler{
+		infoClient: infoClient,
+		lgr:        lgr,
+	}
+}
+
+// @Summary      Song info cache stats
+// @Description  Hit and miss counters of the cache in front of the song info service since the start of the process.
+// @Description  negative_hits are the hits for songs the service has no data for, they are included in the other hits
+// @Tags         cache
+// @Success      200  {object} model.SongInfoCacheStats
+// @Router       /cache/song-info/stats [get]
+func (ch *cacheHandler) GetSongInfoCacheStats(c *fiber.Ctx) error {
+	return c.JSON(ch.infoClient.Stats())
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/audit"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewEditorMiddleware takes the editor from the X-Editor header so the
+// song history can tell who made a change.
+func NewEditorMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if editor := strings.TrimSpace(c.Get(audit.HeaderEditor)); editor != "" {
+			c.Locals(audit.EditorKey, editor)
+		}
+		return c.Next()
+	}
+}
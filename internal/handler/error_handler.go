@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const (
+	MIMEApplicationProblemJSON = "application/problem+json"
+	internalErrorDetail        = "The server encountered an unexpected error"
+)
+
+var problemTypes = []struct {
+	kind    error
+	status  int
+	typeURI string
+}{
+	{apperrors.ErrNotFound, fiber.StatusNotFound, "/problems/not-found"},
+	{apperrors.ErrConflict, fiber.StatusConflict, "/problems/conflict"},
+	{apperrors.ErrPreconditionFailed, fiber.StatusPreconditionFailed, "/problems/precondition-failed"},
+	{apperrors.ErrValidation, fiber.StatusUnprocessableEntity, "/problems/validation"},
+	{apperrors.ErrUpstreamNotFound, fiber.StatusBadGateway, "/problems/upstream-not-found"},
+	{apperrors.ErrUpstreamUnavailable, fiber.StatusServiceUnavailable, "/problems/upstream-unavailable"},
+}
+
+// NewErrorHandler is the fiber.Config.ErrorHandler of the application: every
+// handler returns its errors and this function turns them into problem+json.
+// Only messages of apperrors.Error and fiber.Error reach the client, anything
+// else (SQL errors, recovered panics) is logged and answered with a generic 500.
+func NewErrorHandler(lgr *logger.Logger) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		problem := newProblem(err)
+		problem.Instance = c.OriginalURL()
+		problem.RequestID = c.GetRespHeader(fiber.HeaderXRequestID)
+
+		if problem.Status >= fiber.StatusInternalServerError {
+			lgr.ErrorLogger.Printf("[%s] %s %s failed: %v\n", problem.RequestID, c.Method(), c.Path(), err)
+		} else {
+			lgr.DebugLogger.Printf("[%s] %s %s failed: %v\n", problem.RequestID, c.Method(), c.Path(), err)
+		}
+
+		body, err := json.Marshal(problem)
+		if err != nil {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		c.Set(fiber.HeaderContentType, MIMEApplicationProblemJSON)
+		return c.Status(problem.Status).Send(body)
+	}
+}
+
+// NewPanicHandler logs the stack of a panic recovered by the recover
+// middleware; the panic itself is then passed to the error handler.
+func NewPanicHandler(lgr *logger.Logger) func(c *fiber.Ctx, rec interface{}) {
+	return func(c *fiber.Ctx, rec interface{}) {
+		lgr.ErrorLogger.Printf("[%s] Caught panic in %s %s: %v\n%s", c.GetRespHeader(fiber.HeaderXRequestID), c.Method(), c.Path(), rec, debug.Stack())
+	}
+}
+
+func newProblem(err error) model.Problem {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return model.Problem{
+			Type:   "about:blank",
+			Title:  utils.StatusMessage(fiberErr.Code),
+			Status: fiberErr.Code,
+			Detail: fiberErr.Message,
+		}
+	}
+
+	for _, problemType := range problemTypes {
+		if errors.Is(err, problemType.kind) {
+			problem := model.Problem{
+				Type:   problemType.typeURI,
+				Title:  utils.StatusMessage(problemType.status),
+				Status: problemType.status,
+			}
+			var appErr *apperrors.Error
+			if errors.As(err, &appErr) {
+				problem.Detail = appErr.Message
+				problem.Extensions = appErr.Extensions
+			}
+			return problem
+		}
+	}
+
+	return model.Problem{
+		Type:   "about:blank",
+		Title:  utils.StatusMessage(fiber.StatusInternalServerError),
+		Status: fiber.StatusInternalServerError,
+		Detail: internalErrorDetail,
+	}
+}
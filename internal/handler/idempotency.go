@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// replayedHeaders are the response headers stored with the body.
+var replayedHeaders = []string{fiber.HeaderContentType, fiber.HeaderLocation, fiber.HeaderContentLocation, fiber.HeaderETag}
+
+// NewIdempotencyMiddleware makes a route safe to retry: the first response
+// for an Idempotency-Key is stored for ttl and sent again for every repeat
+// with the same request. Reusing the key for a different request is a
+// validation error. Server errors and panics are not stored, so they can be
+// retried. While the first request runs, the key is only held for lease.
+func NewIdempotencyMiddleware(repo repository.IdempotencyRepository, lease time.Duration, ttl time.Duration, lgr *logger.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key is too long")
+		}
+
+		fingerprint := requestFingerprint(c)
+		stored, err := repo.ReserveKey(c.Context(), key, fingerprint, lease)
+		if err != nil {
+			return err
+		}
+		if stored != nil {
+			switch {
+			case stored.Fingerprint != fingerprint:
+				return apperrors.New(apperrors.ErrValidation, "Idempotency-Key was already used for a different request")
+			case stored.StatusCode == 0:
+				return apperrors.New(apperrors.ErrConflict, "a request with this Idempotency-Key is still being processed")
+			}
+			lgr.DebugLogger.Printf("Replaying response for idempotency key %q\n", key)
+			for name, value := range stored.Headers {
+				c.Set(name, value)
+			}
+			c.Set(HeaderIdempotentReplayed, "true")
+			return c.Status(stored.StatusCode).Send(stored.Body)
+		}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				repo.ReleaseKey(c.Context(), key)
+				panic(rec)
+			}
+		}()
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				repo.ReleaseKey(c.Context(), key)
+				return err
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			repo.ReleaseKey(c.Context(), key)
+			return nil
+		}
+		response := model.IdempotentResponse{
+			StatusCode: status,
+			Headers:    make(map[string]string),
+			Body:       append([]byte(nil), c.Response().Body()...),
+		}
+		for _, name := range replayedHeaders {
+			if value := c.GetRespHeader(name); value != "" {
+				response.Headers[name] = value
+			}
+		}
+		if err := repo.SaveResponse(c.Context(), key, response, ttl); err != nil {
+			repo.ReleaseKey(c.Context(), key)
+		}
+		return nil
+	}
+}
+
+// requestFingerprint identifies a request by method, URL and body. JSON
+// bodies are compacted so that formatting alone does not make a difference.
+func requestFingerprint(c *fiber.Ctx) string {
+	body := c.Body()
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, body); err == nil {
+		body = compacted.Bytes()
+	}
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
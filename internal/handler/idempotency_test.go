@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+)
+
+// fakeIdempotencyRepository keeps the keys in memory and records for how
+// long each one was reserved or stored.
+type fakeIdempotencyRepository struct {
+	responses map[string]model.IdempotentResponse
+	holds     map[string]time.Duration
+}
+
+func (fr *fakeIdempotencyRepository) ReserveKey(ctx context.Context, key string, fingerprint string, lease time.Duration) (*model.IdempotentResponse, error) {
+	if response, ok := fr.responses[key]; ok {
+		return &response, nil
+	}
+	fr.responses[key] = model.IdempotentResponse{Fingerprint: fingerprint}
+	fr.holds[key] = lease
+	return nil, nil
+}
+
+func (fr *fakeIdempotencyRepository) SaveResponse(ctx context.Context, key string, response model.IdempotentResponse, ttl time.Duration) error {
+	response.Fingerprint = fr.responses[key].Fingerprint
+	fr.responses[key] = response
+	fr.holds[key] = ttl
+	return nil
+}
+
+func (fr *fakeIdempotencyRepository) ReleaseKey(ctx context.Context, key string) error {
+	delete(fr.responses, key)
+	delete(fr.holds, key)
+	return nil
+}
+
+func (fr *fakeIdempotencyRepository) PurgeExpiredKeys(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	const lease, ttl = time.Minute, 24 * time.Hour
+	tests := []struct {
+		name       string
+		handler    fiber.Handler
+		wantStatus int
+		wantKept   bool
+	}{
+		{
+			name:       "response is stored for the ttl",
+			handler:    func(c *fiber.Ctx) error { return c.Status(fiber.StatusCreated).SendString("created") },
+			wantStatus: fiber.StatusCreated,
+			wantKept:   true,
+		},
+		{
+			name:       "client errors are stored",
+			handler:    func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusBadRequest, "bad request") },
+			wantStatus: fiber.StatusBadRequest,
+			wantKept:   true,
+		},
+		{
+			name:       "server errors release the key",
+			handler:    func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusInternalServerError, "boom") },
+			wantStatus: fiber.StatusInternalServerError,
+		},
+		{
+			name:       "panics release the key",
+			handler:    func(c *fiber.Ctx) error { panic("boom") },
+			wantStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	lgr := &logger.Logger{
+		InfoLogger:  log.New(io.Discard, "", 0),
+		DebugLogger: log.New(io.Discard, "", 0),
+		ErrorLogger: log.New(io.Discard, "", 0),
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeIdempotencyRepository{responses: map[string]model.IdempotentResponse{}, holds: map[string]time.Duration{}}
+			app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler(lgr)})
+			app.Use(recover.New())
+			app.Post("/songs", NewIdempotencyMiddleware(repo, lease, ttl, lgr), tt.handler)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/songs", strings.NewReader(`{"group":"Muse"}`))
+			req.Header.Set(HeaderIdempotencyKey, "key-1")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			hold, kept := repo.holds["key-1"]
+			if kept != tt.wantKept {
+				t.Fatalf("key kept = %v, want %v", kept, tt.wantKept)
+			}
+			if kept && hold != ttl {
+				t.Errorf("key kept for %s, want %s", hold, ttl)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareLease(t *testing.T) {
+	const lease, ttl = time.Minute, 24 * time.Hour
+	lgr := &logger.Logger{
+		InfoLogger:  log.New(io.Discard, "", 0),
+		DebugLogger: log.New(io.Discard, "", 0),
+		ErrorLogger: log.New(io.Discard, "", 0),
+	}
+	repo := &fakeIdempotencyRepository{responses: map[string]model.IdempotentResponse{}, holds: map[string]time.Duration{}}
+	app := fiber.New(fiber.Config{ErrorHandler: NewErrorHandler(lgr)})
+	app.Post("/songs", NewIdempotencyMiddleware(repo, lease, ttl, lgr), func(c *fiber.Ctx) error {
+		if hold := repo.holds["key-1"]; hold != lease {
+			t.Errorf("key held for %s while the request runs, want %s", hold, lease)
+		}
+		return c.Status(fiber.StatusCreated).SendString("created")
+	})
+
+	for i, wantReplayed := range []string{"", "true"} {
+		req := httptest.NewRequest(fiber.MethodPost, "/songs", strings.NewReader(`{"group":"Muse"}`))
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusCreated || resp.Header.Get(HeaderIdempotentReplayed) != wantReplayed {
+			t.Errorf("request %d: status = %d, replayed = %q", i, resp.StatusCode, resp.Header.Get(HeaderIdempotentReplayed))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/controller"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JobHandler interface {
+	GetJob(c *fiber.Ctx) error
+	RetryJob(c *fiber.Ctx) error
+}
+
+type jobHandler struct {
+	controller controller.JobController
+	lgr        *logger.Logger
+}
+
+func NewJobHandler(controller controller.JobController, lgr *logger.Logger) JobHandler {
+	return &jobHandler{
+		controller: controller,
+		lgr:        lgr,
+	}
+}
+
+// @Summary      Get a job
+// @Description  Poll the state of an asynchronous song insert: queued, running, succeeded or dead.
+// @Description  A succeeded job carries the sound_id of the song
+// @Tags         jobs
+// @Param        job_id path     int     true   "ID of the job"
+// @Success      200  {object} model.SongJob
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /jobs/{job_id} [get]
+func (jh *jobHandler) GetJob(c *fiber.Ctx) error {
+	jobID, err := strconv.ParseInt(c.Params("job_id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid job ID")
+	}
+
+	job, err := jh.controller.GetJob(c.Context(), jobID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(job)
+}
+
+// @Summary      Retry a dead job
+// @Description  Queue a dead job again with a fresh set of attempts
+// @Tags         jobs
+// @Param        job_id path     int     true   "ID of the job"
+// @Success      202  {object} model.SongJob
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      409  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /jobs/{job_id}/retry [post]
+func (jh *jobHandler) RetryJob(c *fiber.Ctx) error {
+	jobID, err := strconv.ParseInt(c.Params("job_id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid job ID")
+	}
+
+	job, err := jh.controller.RetryJob(c.Context(), jobID)
+	if err != nil {
+		return err
+	}
+
+	jh.lgr.InfoLogger.Printf("Job %d queued again\n", jobID)
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
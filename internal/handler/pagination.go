@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// pagination is shared by every list endpoint: it reads page/page_size,
+// sets X-Total-Count and RFC 8288 Link headers and, when the client asks
+// for it with envelope=true, wraps the items in a model.PageEnvelope.
+type pagination struct {
+	Page     int
+	PageSize int
+	Envelope bool
+}
+
+// maxPageSize bounds page_size and limit, so that every page costs about the
+// same however the client asks.
+const maxPageSize = 100
+
+func getPagination(c *fiber.Ctx, defaultPageSize int, lgr *logger.Logger) (pagination, error) {
+	pageSize, err := getPageSize(c, "page_size", defaultPageSize, lgr)
+	if err != nil {
+		return pagination{}, err
+	}
+	envelope, _ := strconv.ParseBool(c.Query("envelope", "false"))
+	return pagination{
+		Page:     getQueryInt(c, "page", 1, lgr),
+		PageSize: pageSize,
+		Envelope: envelope,
+	}, nil
+}
+
+func (p pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+func (p pagination) totalPages(total int) int {
+	return (total + p.PageSize - 1) / p.PageSize
+}
+
+func (p pagination) respond(c *fiber.Ctx, items interface{}, total int) error {
+	totalPages := p.totalPages(total)
+	lastPage := totalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := []string{
+		formatLink(c, "first", "page", strconv.Itoa(1)),
+		formatLink(c, "last", "page", strconv.Itoa(lastPage)),
+	}
+	if p.Page < totalPages {
+		links = append(links, formatLink(c, "next", "page", strconv.Itoa(p.Page+1)))
+	}
+	if p.Page > 1 {
+		prevPage := p.Page - 1
+		if prevPage > lastPage {
+			prevPage = lastPage
+		}
+		links = append(links, formatLink(c, "prev", "page", strconv.Itoa(prevPage)))
+	}
+	c.Set("Link", strings.Join(links, ", "))
+	c.Set("X-Total-Count", strconv.Itoa(total))
+
+	if !p.Envelope {
+		return c.JSON(items)
+	}
+	return c.JSON(model.PageEnvelope{
+		Items:      items,
+		Page:       p.Page,
+		PageSize:   p.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+func setCursorLinks(c *fiber.Ctx, nextCursor *string, prevCursor *string) {
+	var links []string
+	if nextCursor != nil {
+		links = append(links, formatLink(c, "next", "cursor", *nextCursor))
+	}
+	if prevCursor != nil {
+		links = append(links, formatLink(c, "prev", "cursor", *prevCursor))
+	}
+	if len(links) > 0 {
+		c.Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func formatLink(c *fiber.Ctx, rel string, key string, value string) string {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	query.Set(key, value)
+	return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, c.BaseURL(), c.Path(), query.Encode(), rel)
+}
+
+// getPageSize reads a page size parameter and rejects values above
+// maxPageSize.
+func getPageSize(c *fiber.Ctx, key string, defaultValue int, lgr *logger.Logger) (int, error) {
+	value := getQueryInt(c, key, defaultValue, lgr)
+	if value > maxPageSize {
+		return 0, apperrors.New(apperrors.ErrValidation, fmt.Sprintf("%s must not be greater than %d", key, maxPageSize))
+	}
+	return value, nil
+}
+
+func getQueryInt(c *fiber.Ctx, key string, defaultValue int, lgr *logger.Logger) int {
+	valueStr := c.Query(key, strconv.Itoa(defaultValue))
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 1 {
+		lgr.DebugLogger.Printf("Invalid %s parameter, using default: %d\n", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
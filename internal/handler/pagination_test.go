@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGetPagination(t *testing.T) {
+	lgr := &logger.Logger{
+		InfoLogger:  log.New(io.Discard, "", 0),
+		DebugLogger: log.New(io.Discard, "", 0),
+		ErrorLogger: log.New(io.Discard, "", 0),
+	}
+	tests := []struct {
+		name    string
+		query   string
+		want    pagination
+		wantErr error
+	}{
+		{name: "defaults", query: "", want: pagination{Page: 1, PageSize: 10}},
+		{name: "page and size", query: "?page=3&page_size=25&envelope=true", want: pagination{Page: 3, PageSize: 25, Envelope: true}},
+		{name: "maximum size", query: "?page_size=100", want: pagination{Page: 1, PageSize: 100}},
+		{name: "size above the maximum", query: "?page_size=101", wantErr: apperrors.ErrValidation},
+		{name: "huge size", query: "?page_size=2000000000", wantErr: apperrors.ErrValidation},
+		{name: "size out of int range", query: "?page_size=99999999999999999999", want: pagination{Page: 1, PageSize: 10}},
+		{name: "invalid values", query: "?page=0&page_size=-5", want: pagination{Page: 1, PageSize: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got pagination
+			var err error
+			app := fiber.New()
+			app.Get("/songs", func(c *fiber.Ctx) error {
+				got, err = getPagination(c, 10, lgr)
+				return nil
+			})
+			if _, testErr := app.Test(httptest.NewRequest(fiber.MethodGet, "/songs"+tt.query, nil)); testErr != nil {
+				t.Fatal(testErr)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("getPagination() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary      Insert songs in a batch
+// @Description  Insert many songs at once. Details are fetched from the song info service concurrently,
+// @Description  each item may set its own mode like in POST /songs.
+// @Description  With atomic=true (default) the songs are stored in one transaction and a single failure stores nothing;
+// @Description  with atomic=false every song is stored on its own. Each item gets a result: created, conflict,
+// @Description  upstream_error, invalid, aborted or error. The status is 201 when every song was created, otherwise 207
+// @Tags         songs
+// @Param        songRequests    body    []model.SongRequest true "Songs to insert"
+// @Param        atomic          query   bool    false  "Store all songs in one transaction"
+// @Param        Idempotency-Key header  string  false  "Makes retries safe: repeats with the same key get the stored response"
+// @Param        X-Editor        header  string  false  "Who makes the change, recorded in the song history"
+// @Success      201  {object} model.SongBatchResponse
+// @Success      207  {object} model.SongBatchResponse
+// @Failure      400  {object} model.Problem
+// @Failure      422  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/batch [post]
+func (sh *songHandler) InsertSongs(c *fiber.Ctx) error {
+	var songRequests []model.SongRequest
+	if err := parseSongRequest(c, &songRequests); err != nil {
+		return err
+	}
+
+	atomic, err := strconv.ParseBool(c.Query("atomic", "true"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid atomic: "+c.Query("atomic"))
+	}
+
+	response, err := sh.controller.InsertSongs(c.Context(), songRequests, atomic)
+	if err != nil {
+		return err
+	}
+
+	status := fiber.StatusCreated
+	if response.Failed > 0 {
+		status = fiber.StatusMultiStatus
+	}
+	return c.Status(status).JSON(response)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+func getSongFilter(c *fiber.Ctx) (model.SongFilter, error) {
+	filter := model.SongFilter{
+		Group:    c.Query("group"),
+		Song:     c.Query("song"),
+		LinkHost: c.Query("link_host"),
+	}
+
+	switch songMatch := c.Query("song_match", "exact"); songMatch {
+	case "exact":
+	case "prefix":
+		filter.SongPrefix = true
+	default:
+		return filter, fmt.Errorf("Invalid song_match: %s", songMatch)
+	}
+
+	var err error
+	if filter.ReleasedAfter, err = getQueryDate(c, "released_after"); err != nil {
+		return filter, err
+	}
+	if filter.ReleasedBefore, err = getQueryDate(c, "released_before"); err != nil {
+		return filter, err
+	}
+
+	if hasTextStr := c.Query("has_text"); hasTextStr != "" {
+		hasText, err := strconv.ParseBool(hasTextStr)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid has_text: %s", hasTextStr)
+		}
+		filter.HasText = &hasText
+	}
+
+	return filter, nil
+}
+
+func getQueryDate(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := model.ParseDate(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s: %s", key, value)
+	}
+	t := date.Time()
+	return &t, nil
+}
@@ -2,214 +2,509 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/controller"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
 	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
 	"github.com/gofiber/fiber/v2"
 )
 
+const MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+
 type SongHandler interface {
 	GetSongs(c *fiber.Ctx) error
+	SearchSongs(c *fiber.Ctx) error
+	SuggestSongs(c *fiber.Ctx) error
 	GetSong(c *fiber.Ctx) error
 	GetSongText(c *fiber.Ctx) error
 	InsertSong(c *fiber.Ctx) error
+	InsertSongs(c *fiber.Ctx) error
 	UpdateSong(c *fiber.Ctx) error
+	PatchSong(c *fiber.Ctx) error
 	DeleteSong(c *fiber.Ctx) error
+	GetTrash(c *fiber.Ctx) error
+	RestoreSong(c *fiber.Ctx) error
+	GetSongRevisions(c *fiber.Ctx) error
+	DiffSongRevision(c *fiber.Ctx) error
+	RevertSong(c *fiber.Ctx) error
+	RefreshSong(c *fiber.Ctx) error
 }
 
 type songHandler struct {
-	ctx        context.Context
-	controller controller.SongController
-	lgr        *logger.Logger
+	ctx           context.Context
+	controller    controller.SongController
+	jobController controller.JobController
+	lgr           *logger.Logger
 }
 
-func NewSongHandler(controller controller.SongController, lgr *logger.Logger) SongHandler {
+func NewSongHandler(controller controller.SongController, jobController controller.JobController, lgr *logger.Logger) SongHandler {
 	return &songHandler{
-		controller: controller,
-		ctx:        context.Background(),
-		lgr:        lgr,
+		controller:    controller,
+		jobController: jobController,
+		ctx:           context.Background(),
+		lgr:           lgr,
 	}
 }
 
 // @Summary      Get all songs
-// @Description  Retrieve a list of songs with pagination and sorting
+// @Description  Retrieve a list of songs with pagination and sorting.
+// @Description  Passing cursor or limit switches to keyset pagination: the response is then a model.SongCursorPage
+// @Description  whose next_cursor/prev_cursor can be passed back as cursor to walk the list.
 // @Tags         songs
-// @Param        sort      query    string  false  "Field to sort by" Enums(sound_id,text_length,song,release_date)
-// @Param        page      query    int     true   "Page number"
-// @Param        page_size query    int     true   "Number of items per page"
+// @Param        sort            query    string  false  "Field to sort by" Enums(sound_id,text_length,song,release_date)
+// @Param        page            query    int     false  "Page number"
+// @Param        page_size       query    int     false  "Number of items per page, up to 100"
+// @Param        cursor          query    string  false  "Opaque cursor from next_cursor or prev_cursor"
+// @Param        limit           query    int     false  "Number of items per page in cursor mode, up to 100"
+// @Param        group           query    string  false  "Group name, case-insensitive exact match"
+// @Param        song            query    string  false  "Song title, case-insensitive"
+// @Param        song_match      query    string  false  "How to match the song title" Enums(exact,prefix)
+// @Param        released_after  query    string  false  "Released on or after this date (02.01.2006 or 2006-01-02)"
+// @Param        released_before query    string  false  "Released on or before this date (02.01.2006 or 2006-01-02)"
+// @Param        has_text        query    bool    false  "Only songs with (true) or without (false) lyrics"
+// @Param        link_host       query    string  false  "Host of the song link, subdomains included"
+// @Param        envelope        query    bool    false  "Wrap the page into a model.PageEnvelope"
 // @Success      200  {array}  model.Song
-// @Failure      500  {object} map[string]interface{}
+// @Header       200  {integer} X-Total-Count "Total number of matching songs"
+// @Header       200  {string}  Link          "RFC 8288 first/last/next/prev links"
+// @Failure      400  {object} model.Problem
+// @Failure      422  {object} model.Problem
+// @Failure      500  {object} model.Problem
 // @Router       /songs [get]
 func (sh *songHandler) GetSongs(c *fiber.Ctx) error {
+	filter, err := getSongFilter(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if c.Query("cursor") != "" || c.Query("limit") != "" {
+		return sh.getSongsByCursor(c, filter)
+	}
 	sortParam := c.Query("sort", "sound_id")
-	page := getPage(c, 1, sh.lgr)
-	pageSize := getPageSize(c, 10, sh.lgr)
+	pg, err := getPagination(c, 10, sh.lgr)
+	if err != nil {
+		return err
+	}
+
+	paginatedSongs, total, err := sh.controller.GetSongs(c.Context(), filter, sortParam, pg.Page, pg.PageSize)
+	if err != nil {
+		return err
+	}
+
+	sh.lgr.InfoLogger.Printf("Returned %d of %d songs\n", len(paginatedSongs), total)
+	return pg.respond(c, paginatedSongs, total)
+}
+
+func (sh *songHandler) getSongsByCursor(c *fiber.Ctx, filter model.SongFilter) error {
+	limit, err := getPageSize(c, "limit", 10, sh.lgr)
+	if err != nil {
+		return err
+	}
+
+	songPage, err := sh.controller.GetSongsByCursor(c.Context(), filter, c.Query("sort"), c.Query("cursor"), limit)
+	if err != nil {
+		return err
+	}
+
+	sh.lgr.InfoLogger.Printf("Returned %d songs by cursor\n", len(songPage.Items))
+	setCursorLinks(c, songPage.NextCursor, songPage.PrevCursor)
+	return c.JSON(songPage)
+}
+
+// @Summary      Search songs
+// @Description  Full-text search over song titles, groups and lyrics, ranked by relevance.
+// @Description  Each result carries a headline with the matching lyrics fragment highlighted by <b></b>
+// @Tags         songs
+// @Param        q         query    string  true   "Search query, web search syntax (quotes, or, -)"
+// @Param        page      query    int     false  "Page number"
+// @Param        page_size query    int     false  "Number of items per page, up to 100"
+// @Param        envelope  query    bool    false  "Wrap the page into a model.PageEnvelope"
+// @Success      200  {array}  model.SongSearchResult
+// @Header       200  {integer} X-Total-Count "Total number of matching songs"
+// @Header       200  {string}  Link          "RFC 8288 first/last/next/prev links"
+// @Failure      422  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/search [get]
+func (sh *songHandler) SearchSongs(c *fiber.Ctx) error {
+	pg, err := getPagination(c, 10, sh.lgr)
+	if err != nil {
+		return err
+	}
+
+	results, total, err := sh.controller.SearchSongs(c.Context(), c.Query("q"), pg.Page, pg.PageSize)
+	if err != nil {
+		return err
+	}
+
+	sh.lgr.InfoLogger.Printf("Returned %d of %d search results\n", len(results), total)
+	return pg.respond(c, results, total)
+}
 
-	paginatedSongs, err := sh.controller.GetSongs(c.Context(), sortParam, page, pageSize)
+// @Summary      Suggest songs
+// @Description  Typo-tolerant autocomplete over group and song titles, ordered by trigram similarity
+// @Tags         songs
+// @Param        q     query    string  true   "Partial or misspelled group or song title"
+// @Param        limit query    int     false  "Maximum number of suggestions (up to 50)"
+// @Success      200  {array}  model.SongSuggestion
+// @Failure      422  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/suggest [get]
+func (sh *songHandler) SuggestSongs(c *fiber.Ctx) error {
+	limit := getQueryInt(c, "limit", 10, sh.lgr)
+
+	suggestions, err := sh.controller.SuggestSongs(c.Context(), c.Query("q"), limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	sh.lgr.InfoLogger.Printf("Returned %d songs\n", len(paginatedSongs))
-	return c.JSON(paginatedSongs)
+	sh.lgr.InfoLogger.Printf("Returned %d suggestions\n", len(suggestions))
+	return c.JSON(suggestions)
 }
 
+// @Summary      Get a song
+// @Description  Retrieve a song by ID. The ETag is the song version; supports conditional requests with If-None-Match
+// @Tags         songs
+// @Param        song_id       path     int     true   "ID of the song"
+// @Param        If-None-Match header   string  false  "ETag of a cached copy"
+// @Success      200  {object} model.Song
+// @Header       200  {string} ETag "Entity tag of the song"
+// @Success      304  "Not Modified"
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/{song_id} [get]
 func (sh *songHandler) GetSong(c *fiber.Ctx) error {
-	sh.lgr.DebugLogger.Println("GetSong is not implemented")
-	return nil
+	songIDStr := c.Params("song_id")
+	songId, err := strconv.Atoi(songIDStr)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song_id")
+	}
+
+	song, err := sh.controller.GetSong(c.Context(), songId)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+	return sendWithETag(c, model.VersionETag(song.Version), body)
 }
 
 // @Summary      Get song text
 // @Description  Retrieve the text of a song with pagination
 // @Tags         songs
 // @Param        song_id   path     int     true   "ID of the song"
-// @Param        page      query    int     true   "Page number"
-// @Param        page_size query    int     true   "Number of items per page"
+// @Param        page      query    int     false  "Page number"
+// @Param        page_size query    int     false  "Number of items per page, up to 100"
+// @Param        envelope  query    bool    false  "Wrap the page into a model.PageEnvelope"
 // @Success      200  {array}  string
-// @Failure      400  {object} map[string]interface{}
-// @Failure      500  {object} map[string]interface{}
+// @Header       200  {integer} X-Total-Count "Total number of verses"
+// @Header       200  {string}  Link          "RFC 8288 first/last/next/prev links"
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      422  {object} model.Problem
+// @Failure      500  {object} model.Problem
 // @Router       /songs/{song_id}/text [get]
 func (sh *songHandler) GetSongText(c *fiber.Ctx) error {
 	songIDStr := c.Params("song_id")
 	songId, err := strconv.Atoi(songIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid song_id"})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song_id")
 	}
 
-	page := getPage(c, 1, sh.lgr)
-	pageSize := getPageSize(c, 1, sh.lgr)
+	pg, err := getPagination(c, 1, sh.lgr)
+	if err != nil {
+		return err
+	}
 
-	verses, err := sh.controller.GetSongText(c.Context(), songId, pageSize, page)
+	verses, total, err := sh.controller.GetSongText(c.Context(), songId, pg.PageSize, pg.Page)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
-	return c.JSON(verses)
+	return pg.respond(c, verses, total)
 }
 
 // @Summary      Insert a new song
-// @Description  Insert a new song from a SongRequest
+// @Description  Insert a new song from a SongRequest.
+// @Description  mode enrich (default) takes the details from the song info service, manual takes them from the request
+// @Description  without asking the service, enrich_or_fallback takes them from the request when the service has no data or is down.
+// @Description  The details of the request also fill fields the service leaves empty; "sources" tells where each field came from.
+// @Description  When near-identical songs already exist they are listed in "similar" as a warning.
+// @Description  A song with the same group and title (ignoring case) is a conflict whose problem details carry its sound_id;
+// @Description  with on_conflict=update that song is refreshed from the song info service and returned with 200 instead.
+// @Description  With async=true the insert runs in the background: the response is 202 with a job to poll at /jobs/{job_id}
 // @Tags         songs
 // @Param        songRequest body    model.SongRequest true "Song request object"
-// @Success      201  {object} map[string]interface{}
-// @Failure      400  {object} map[string]interface{}
-// @Failure      500  {object} map[string]interface{}
+// @Param        on_conflict query   string  false  "What to do when the song already exists" Enums(error,update)
+// @Param        async       query   bool    false  "Insert in the background and return a job"
+// @Param        Idempotency-Key header string false "Makes retries safe: repeats with the same key get the stored response"
+// @Param        X-Editor    header  string  false  "Who makes the change, recorded in the song history"
+// @Success      201  {object} model.SongInsertResult
+// @Header       201  {string} Location "URL of the created song"
+// @Header       201  {string} ETag     "Entity tag of the song"
+// @Header       201  {string} Idempotent-Replayed "true when the response was stored for the Idempotency-Key"
+// @Success      200  {object} model.SongInsertResult
+// @Success      202  {object} model.SongJob
+// @Header       202  {string} Location "URL of the job"
+// @Failure      400  {object} model.Problem
+// @Failure      409  {object} model.Problem
+// @Failure      422  {object} model.Problem
+// @Failure      502  {object} model.Problem
+// @Failure      503  {object} model.Problem
+// @Failure      500  {object} model.Problem
 // @Router       /songs [post]
 func (sh *songHandler) InsertSong(c *fiber.Ctx) error {
 	var songRequest model.SongRequest
-	if err := c.BodyParser(&songRequest); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := parseSongRequest(c, &songRequest); err != nil {
+		return err
 	}
 
 	if songRequest.Group == "" || songRequest.Song == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Group and Song fields are required",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Group and Song fields are required")
+	}
+
+	var refreshExisting bool
+	switch onConflict := c.Query("on_conflict", "error"); onConflict {
+	case "error":
+	case "update":
+		refreshExisting = true
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid on_conflict: "+onConflict)
+	}
+
+	async, err := strconv.ParseBool(c.Query("async", "false"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid async: "+c.Query("async"))
 	}
 
 	sh.lgr.DebugLogger.Printf("InsertSong called with group: %s, song: %s\n", songRequest.Group, songRequest.Song)
 
-	if err := sh.controller.InsertSong(c.Context(), songRequest); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	if async {
+		job, err := sh.jobController.EnqueueSong(c.Context(), songRequest, refreshExisting)
+		if err != nil {
+			return err
+		}
+		c.Location(fmt.Sprintf("/jobs/%d", job.ID))
+		return c.Status(fiber.StatusAccepted).JSON(job)
 	}
 
-	sh.lgr.InfoLogger.Printf("Song inserted successfully\n")
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Song inserted successfully",
-	})
+	result, created, err := sh.controller.InsertSong(c.Context(), songRequest, refreshExisting)
+	if err != nil {
+		return err
+	}
+
+	location := fmt.Sprintf("/songs/%d", result.SoundId)
+	c.Set(fiber.HeaderETag, model.VersionETag(result.Version))
+	if !created {
+		sh.lgr.InfoLogger.Printf("Song %d refreshed instead of inserting a duplicate\n", result.SoundId)
+		c.Set(fiber.HeaderContentLocation, location)
+		return c.JSON(result)
+	}
+
+	sh.lgr.InfoLogger.Printf("Song %d inserted successfully\n", result.SoundId)
+	c.Location(location)
+	return c.Status(fiber.StatusCreated).JSON(result)
 }
 
-// @Summary      Update an existing song
-// @Description  Update a song by ID
+// @Summary      Replace an existing song
+// @Description  Replace a song by ID. Every field must be present; releaseDate, text and link may be null.
+// @Description  releaseDate accepts YYYY-MM-DD and DD.MM.YYYY. pinnedFields is optional and kept when absent
 // @Tags         songs
 // @Param        song_id path     int     true   "ID of the song"
+// @Param        If-Match header   string  false  "ETag (version) the change is based on"
+// @Param        X-Editor header   string  false  "Who makes the change, recorded in the song history"
 // @Param        song    body     model.Song true "Updated song object"
 // @Success      200  {object} map[string]interface{}
-// @Failure      400  {object} map[string]interface{}
-// @Failure      500  {object} map[string]interface{}
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      422  {object} model.Problem
+// @Failure      409  {object} model.Problem
+// @Failure      412  {object} model.Problem
+// @Failure      500  {object} model.Problem
 // @Router       /songs/{song_id} [put]
 func (sh *songHandler) UpdateSong(c *fiber.Ctx) error {
 	songIDStr := c.Params("song_id")
 	songID, err := strconv.Atoi(songIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid song ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song ID")
 	}
 
-	var song model.Song
-	err = c.BodyParser(&song)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid JSON body",
-		})
+	var song model.SongPatch
+	if err := parseSongPatch(c.Body(), &song); err != nil {
+		return err
 	}
 
-	if err := sh.controller.UpdateSong(c.Context(), songID, song); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	updated, err := sh.controller.UpdateSong(c.Context(), songID, song, model.ParseIfMatch(c.Get(fiber.HeaderIfMatch)))
+	if err != nil {
+		return err
 	}
 
 	sh.lgr.InfoLogger.Printf("Song updated successfully\n")
+	c.Set(fiber.HeaderETag, model.VersionETag(updated.Version))
 	return c.JSON(fiber.Map{
 		"message": "Song updated successfully",
 	})
 }
 
+// @Summary      Patch an existing song
+// @Description  Partially update a song with a JSON Merge Patch (RFC 7396): absent keys are kept,
+// @Description  null clears releaseDate, text or link. group and song cannot be cleared.
+// @Description  pinnedFields lists the fields of releaseDate, text and link that a refresh must not overwrite
+// @Tags         songs
+// @Accept       application/merge-patch+json
+// @Param        song_id path     int     true   "ID of the song"
+// @Param        If-Match header   string  false  "ETag (version) the change is based on"
+// @Param        X-Editor header   string  false  "Who makes the change, recorded in the song history"
+// @Param        patch   body     model.Song true "Merge patch"
+// @Success      200  {object} model.Song
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      415  {object} model.Problem
+// @Failure      422  {object} model.Problem
+// @Failure      409  {object} model.Problem
+// @Failure      412  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/{song_id} [patch]
+func (sh *songHandler) PatchSong(c *fiber.Ctx) error {
+	songIDStr := c.Params("song_id")
+	songID, err := strconv.Atoi(songIDStr)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song ID")
+	}
+
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), MIMEApplicationMergePatchJSON) {
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, "Content-Type must be "+MIMEApplicationMergePatchJSON)
+	}
+
+	var patch model.SongPatch
+	if err := parseSongPatch(c.Body(), &patch); err != nil {
+		return err
+	}
+
+	song, err := sh.controller.PatchSong(c.Context(), songID, patch, model.ParseIfMatch(c.Get(fiber.HeaderIfMatch)))
+	if err != nil {
+		return err
+	}
+
+	sh.lgr.InfoLogger.Printf("Song patched successfully\n")
+	c.Set(fiber.HeaderETag, model.VersionETag(song.Version))
+	return c.JSON(song)
+}
+
+func parseSongPatch(body []byte, patch *model.SongPatch) error {
+	err := json.Unmarshal(body, patch)
+	if errors.Is(err, model.ErrInvalidDate) {
+		return apperrors.New(apperrors.ErrValidation, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid JSON body")
+	}
+	return nil
+}
+
+// parseSongRequest reports a malformed releaseDate as a validation error
+// like parseSongPatch does.
+func parseSongRequest(c *fiber.Ctx, out interface{}) error {
+	err := c.BodyParser(out)
+	if errors.Is(err, model.ErrInvalidDate) {
+		return apperrors.New(apperrors.ErrValidation, err.Error())
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid request body")
+	}
+	return nil
+}
+
 // @Summary      Delete a song
-// @Description  Delete a song by ID
+// @Description  Move a song to the trash. It can be restored until the retention period is over
 // @Tags         songs
 // @Param        song_id path     int     true   "ID of the song"
+// @Param        If-Match header   string  false  "ETag (version) the change is based on"
+// @Param        X-Editor header   string  false  "Who makes the change, recorded in the song history"
 // @Success      200  {object} map[string]interface{}
-// @Failure      400  {object} map[string]interface{}
-// @Failure      500  {object} map[string]interface{}
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      412  {object} model.Problem
+// @Failure      500  {object} model.Problem
 // @Router       /songs/{song_id} [delete]
 func (sh *songHandler) DeleteSong(c *fiber.Ctx) error {
 	songIDStr := c.Params("song_id")
 	songID, err := strconv.Atoi(songIDStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid song ID",
-		})
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song ID")
 	}
 
-	if err := sh.controller.DeleteSong(c.Context(), songID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	if err := sh.controller.DeleteSong(c.Context(), songID, model.ParseIfMatch(c.Get(fiber.HeaderIfMatch))); err != nil {
+		return err
 	}
 
 	sh.lgr.InfoLogger.Printf("Song deleted successfully\n")
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Song deleted successfully",
+		"message": "Song moved to the trash",
 	})
 }
 
-func getPage(c *fiber.Ctx, defaultValue int, lgr *logger.Logger) int {
-	pageStr := c.Query("page", strconv.Itoa(defaultValue))
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		lgr.DebugLogger.Printf("Invalid page parameter, using default: %d\n", defaultValue)
-		return defaultValue
+// @Summary      List deleted songs
+// @Description  Retrieve songs in the trash, most recently deleted first.
+// @Description  Songs are purged for good once the retention period is over
+// @Tags         trash
+// @Param        page      query    int     false  "Page number"
+// @Param        page_size query    int     false  "Number of items per page, up to 100"
+// @Param        envelope  query    bool    false  "Wrap the page into a model.PageEnvelope"
+// @Success      200  {array}  model.Song
+// @Header       200  {integer} X-Total-Count "Total number of deleted songs"
+// @Header       200  {string}  Link          "RFC 8288 first/last/next/prev links"
+// @Failure      422  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/trash [get]
+func (sh *songHandler) GetTrash(c *fiber.Ctx) error {
+	pg, err := getPagination(c, 10, sh.lgr)
+	if err != nil {
+		return err
+	}
+
+	songs, total, err := sh.controller.GetTrash(c.Context(), pg.Page, pg.PageSize)
+	if err != nil {
+		return err
 	}
-	return page
+
+	sh.lgr.InfoLogger.Printf("Returned %d of %d deleted songs\n", len(songs), total)
+	return pg.respond(c, songs, total)
 }
 
-func getPageSize(c *fiber.Ctx, defaultValue int, lgr *logger.Logger) int {
-	pageSizeStr := c.Query("page_size", strconv.Itoa(defaultValue))
-	pageSize, err := strconv.Atoi(pageSizeStr)
-	if err != nil || pageSize < 1 {
-		lgr.DebugLogger.Printf("Invalid page_size parameter, using default: %d\n", defaultValue)
-		return defaultValue
+// @Summary      Restore a deleted song
+// @Description  Move a song out of the trash. Fails with a conflict when a song with the same group and title exists by now
+// @Tags         trash
+// @Param        song_id path     int     true   "ID of the song"
+// @Param        X-Editor header   string  false  "Who makes the change, recorded in the song history"
+// @Success      200  {object} model.Song
+// @Header       200  {string} ETag "Entity tag of the song"
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      409  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/{song_id}/restore [post]
+func (sh *songHandler) RestoreSong(c *fiber.Ctx) error {
+	songIDStr := c.Params("song_id")
+	songID, err := strconv.Atoi(songIDStr)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song ID")
+	}
+
+	song, err := sh.controller.RestoreSong(c.Context(), songID)
+	if err != nil {
+		return err
 	}
-	return pageSize
+
+	sh.lgr.InfoLogger.Printf("Song restored successfully\n")
+	c.Set(fiber.HeaderETag, model.VersionETag(song.Version))
+	return c.JSON(song)
 }
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary      Refresh a song from the song info service
+// @Description  Fetch fresh details for the stored group and song and compare them field by field with the song.
+// @Description  Changed fields are applied unless they are pinned; with dry_run=true nothing is written.
+// @Description  applied tells whether the song was written
+// @Tags         songs
+// @Param        song_id  path     int     true   "ID of the song"
+// @Param        dry_run  query    bool    false  "Only report the changes"
+// @Param        If-Match header   string  false  "ETag (version) the change is based on"
+// @Param        X-Editor header   string  false  "Who makes the change, recorded in the song history"
+// @Success      200  {object} model.SongRefresh
+// @Header       200  {string} ETag "Entity tag of the song"
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      409  {object} model.Problem
+// @Failure      412  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Failure      502  {object} model.Problem
+// @Failure      503  {object} model.Problem
+// @Router       /songs/{song_id}/refresh [post]
+func (sh *songHandler) RefreshSong(c *fiber.Ctx) error {
+	songID, err := strconv.Atoi(c.Params("song_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song ID")
+	}
+	dryRun, err := strconv.ParseBool(c.Query("dry_run", "false"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid dry_run: "+c.Query("dry_run"))
+	}
+
+	refresh, err := sh.controller.RefreshSong(c.Context(), songID, dryRun, model.ParseIfMatch(c.Get(fiber.HeaderIfMatch)))
+	if err != nil {
+		return err
+	}
+
+	if refresh.Applied {
+		sh.lgr.InfoLogger.Printf("Song %d refreshed\n", songID)
+	}
+	c.Set(fiber.HeaderETag, model.VersionETag(refresh.Song.Version))
+	return c.JSON(refresh)
+}
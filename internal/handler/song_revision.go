@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary      List song revisions
+// @Description  Retrieve the change history of a song, newest revision first.
+// @Description  The revision number is the song version the change produced
+// @Tags         revisions
+// @Param        song_id   path     int     true   "ID of the song"
+// @Param        page      query    int     false  "Page number"
+// @Param        page_size query    int     false  "Number of items per page, up to 100"
+// @Param        envelope  query    bool    false  "Wrap the page into a model.PageEnvelope"
+// @Success      200  {array}  model.SongRevision
+// @Header       200  {integer} X-Total-Count "Total number of revisions"
+// @Header       200  {string}  Link          "RFC 8288 first/last/next/prev links"
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      422  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/{song_id}/revisions [get]
+func (sh *songHandler) GetSongRevisions(c *fiber.Ctx) error {
+	songID, err := strconv.Atoi(c.Params("song_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid song ID")
+	}
+
+	pg, err := getPagination(c, 10, sh.lgr)
+	if err != nil {
+		return err
+	}
+
+	revisions, total, err := sh.controller.GetSongRevisions(c.Context(), songID, pg.Page, pg.PageSize)
+	if err != nil {
+		return err
+	}
+
+	return pg.respond(c, revisions, total)
+}
+
+// @Summary      Diff a song revision
+// @Description  Line-level diff of the lyrics of a revision against the previous revision or the one given in against
+// @Tags         revisions
+// @Param        song_id  path     int     true   "ID of the song"
+// @Param        revision path     int     true   "Revision number"
+// @Param        against  query    int     false  "Revision to compare with, the previous one by default"
+// @Success      200  {object} model.SongRevisionDiff
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/{song_id}/revisions/{revision}/diff [get]
+func (sh *songHandler) DiffSongRevision(c *fiber.Ctx) error {
+	songID, revision, err := getRevisionParams(c)
+	if err != nil {
+		return err
+	}
+
+	against := 0
+	if c.Query("against") != "" {
+		if against, err = strconv.Atoi(c.Query("against")); err != nil || against < 1 {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid against revision")
+		}
+	}
+
+	diff, err := sh.controller.DiffSongRevision(c.Context(), songID, revision, against)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(diff)
+}
+
+// @Summary      Revert a song to a revision
+// @Description  Restore the content of an old revision. The revert is recorded as a new revision
+// @Tags         revisions
+// @Param        song_id  path     int     true   "ID of the song"
+// @Param        revision path     int     true   "Revision number"
+// @Param        If-Match header   string  false  "ETag (version) the change is based on"
+// @Param        X-Editor header   string  false  "Who makes the change, recorded in the song history"
+// @Success      200  {object} model.Song
+// @Header       200  {string} ETag "Entity tag of the song"
+// @Failure      400  {object} model.Problem
+// @Failure      404  {object} model.Problem
+// @Failure      409  {object} model.Problem
+// @Failure      412  {object} model.Problem
+// @Failure      500  {object} model.Problem
+// @Router       /songs/{song_id}/revisions/{revision}/revert [post]
+func (sh *songHandler) RevertSong(c *fiber.Ctx) error {
+	songID, revision, err := getRevisionParams(c)
+	if err != nil {
+		return err
+	}
+
+	song, err := sh.controller.RevertSong(c.Context(), songID, revision, model.ParseIfMatch(c.Get(fiber.HeaderIfMatch)))
+	if err != nil {
+		return err
+	}
+
+	sh.lgr.InfoLogger.Printf("Song %d reverted to revision %d\n", songID, revision)
+	c.Set(fiber.HeaderETag, model.VersionETag(song.Version))
+	return c.JSON(song)
+}
+
+func getRevisionParams(c *fiber.Ctx) (int, int, error) {
+	songID, err := strconv.Atoi(c.Params("song_id"))
+	if err != nil {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid song ID")
+	}
+	revision, err := strconv.Atoi(c.Params("revision"))
+	if err != nil || revision < 1 {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid revision")
+	}
+	return songID, revision, nil
+}
@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+type providerChain struct {
+	providers []MetadataProvider
+	lgr       *logger.Logger
+}
+
+// NewProviderChain builds the providers described in the file at path. The
+// info_api provider is backed by infoClient. Without a file the chain only
+// has the song info API.
+func NewProviderChain(path string, infoClient client.MusicInfoClient, lgr *logger.Logger) (MetadataProvider, error) {
+	if path == "" {
+		return NewChain([]MetadataProvider{NewInfoAPIProvider(ProviderInfoAPI, infoClient)}, lgr), nil
+	}
+	conf, err := LoadChainConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]MetadataProvider, 0, len(conf.Providers))
+	for _, providerConf := range conf.Providers {
+		var provider MetadataProvider
+		switch providerConf.Type {
+		case ProviderInfoAPI:
+			provider = NewInfoAPIProvider(providerConf.Name, infoClient)
+		case ProviderHTTP:
+			provider = NewHTTPProvider(providerConf, lgr)
+		case ProviderFile:
+			if provider, err = NewFileProvider(providerConf, lgr); err != nil {
+				return nil, err
+			}
+		}
+		providers = append(providers, provider)
+		lgr.InfoLogger.Printf("Metadata provider %d: %s (%s)\n", len(providers), providerConf.Name, providerConf.Type)
+	}
+	return NewChain(providers, lgr), nil
+}
+
+// NewChain asks the providers in order, the first one has the highest
+// priority. Every field is taken from the first provider that has a value
+// for it, so later providers only fill the gaps.
+func NewChain(providers []MetadataProvider, lgr *logger.Logger) MetadataProvider {
+	return &providerChain{
+		providers: providers,
+		lgr:       lgr,
+	}
+}
+
+func (pc *providerChain) Name() string {
+	return "metadata provider chain"
+}
+
+// GetSongDetail stops asking once every field has a value. Failing providers
+// are skipped and the merged details are marked degraded; the chain only
+// fails with their error when no provider had data for the song. A chain of
+// one provider keeps its not-found error.
+func (pc *providerChain) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	var merged *model.SongDetail
+	var failure, missing error
+	for _, provider := range pc.providers {
+		detail, err := provider.GetSongDetail(ctx, group, song)
+		if errors.Is(err, apperrors.ErrUpstreamNotFound) {
+			pc.lgr.DebugLogger.Printf("Metadata provider %s has no data for %s - %s\n", provider.Name(), group, song)
+			missing = err
+			continue
+		}
+		if err != nil {
+			pc.lgr.ErrorLogger.Printf("Metadata provider %s failed for %s - %s: %v\n", provider.Name(), group, song, err)
+			if failure == nil {
+				failure = err
+			}
+			continue
+		}
+		if merged == nil {
+			merged = &model.SongDetail{}
+		}
+		mergeDetail(merged, detail)
+		if complete(merged) {
+			break
+		}
+	}
+
+	switch {
+	case merged != nil:
+		merged.Degraded = failure != nil
+		return merged, nil
+	case failure != nil:
+		return nil, failure
+	case len(pc.providers) == 1:
+		return nil, missing
+	}
+	return nil, apperrors.Wrap(apperrors.ErrUpstreamNotFound, fmt.Sprintf("no metadata provider has data for %s - %s", group, song), client.ErrNotFound)
+}
+
+// mergeDetail fills the empty fields of merged from detail.
+func mergeDetail(merged *model.SongDetail, detail *model.SongDetail) {
+	if merged.ReleaseDate.IsZero() {
+		merged.ReleaseDate = detail.ReleaseDate
+	}
+	if merged.Text == "" {
+		merged.Text = detail.Text
+	}
+	if merged.Link == "" {
+		merged.Link = detail.Link
+	}
+}
+
+func complete(detail *model.SongDetail) bool {
+	return !detail.ReleaseDate.IsZero() && detail.Text != "" && detail.Link != ""
+}
@@ -0,0 +1,102 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+var testDate = time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)
+
+func discardLogger() *logger.Logger {
+	return &logger.Logger{
+		InfoLogger:  log.New(io.Discard, "", 0),
+		DebugLogger: log.New(io.Discard, "", 0),
+		ErrorLogger: log.New(io.Discard, "", 0),
+	}
+}
+
+type fakeProvider struct {
+	name   string
+	detail *model.SongDetail
+	err    error
+}
+
+func (fp fakeProvider) Name() string {
+	return fp.name
+}
+
+func (fp fakeProvider) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	return fp.detail, fp.err
+}
+
+func TestProviderChain(t *testing.T) {
+	down := fakeProvider{name: "down", err: unavailable("down", errors.New("connection refused"))}
+	empty := fakeProvider{name: "empty", err: notFound("empty", "Muse", "Uprising")}
+	lyrics := fakeProvider{name: "lyrics", detail: &model.SongDetail{Text: "Paranoia is in bloom"}}
+	links := fakeProvider{name: "links", detail: &model.SongDetail{Text: "other lyrics", Link: "https://example.com/uprising"}}
+
+	tests := []struct {
+		name      string
+		providers []MetadataProvider
+		want      *model.SongDetail
+		wantErr   error
+	}{
+		{
+			name:      "earlier providers win",
+			providers: []MetadataProvider{lyrics, links},
+			want:      &model.SongDetail{Text: "Paranoia is in bloom", Link: "https://example.com/uprising"},
+		},
+		{
+			name:      "providers without data are skipped",
+			providers: []MetadataProvider{empty, lyrics},
+			want:      &model.SongDetail{Text: "Paranoia is in bloom"},
+		},
+		{
+			name:      "failed provider degrades the result",
+			providers: []MetadataProvider{down, lyrics},
+			want:      &model.SongDetail{Text: "Paranoia is in bloom", Degraded: true},
+		},
+		{
+			name:      "providers after a complete result are not asked",
+			providers: []MetadataProvider{fakeProvider{name: "full", detail: &model.SongDetail{Text: "a", Link: "b", ReleaseDate: model.NewDate(testDate)}}, down},
+			want:      &model.SongDetail{Text: "a", Link: "b", ReleaseDate: model.NewDate(testDate)},
+		},
+		{
+			name:      "failure without data",
+			providers: []MetadataProvider{down, empty},
+			wantErr:   apperrors.ErrUpstreamUnavailable,
+		},
+		{
+			name:      "no provider has data",
+			providers: []MetadataProvider{empty, empty},
+			wantErr:   apperrors.ErrUpstreamNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewChain(tt.providers, discardLogger()).GetSongDetail(context.Background(), "Muse", "Uprising")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetSongDetail() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
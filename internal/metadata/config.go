@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+)
+
+const (
+	ProviderInfoAPI = "info_api"
+	ProviderHTTP    = "http"
+	ProviderFile    = "file"
+
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// ChainConfig is the content of METADATA_PROVIDERS_FILE. Providers are listed
+// from the highest priority to the lowest.
+type ChainConfig struct {
+	Providers []ProviderConfig `json:"providers"`
+}
+
+// ProviderConfig describes one provider. URL, Query and Headers are used by
+// http providers: {group} and {song} are replaced in URL and query values,
+// and $VARS in header values are taken from the environment. Path, Format
+// and Match are used by file providers.
+type ProviderConfig struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Fields    FieldMapping      `json:"fields"`
+	URL       string            `json:"url,omitempty"`
+	Query     map[string]string `json:"query,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	TimeoutMs int               `json:"timeout_ms,omitempty"`
+	Path      string            `json:"path,omitempty"`
+	Format    string            `json:"format,omitempty"`
+	Match     MatchMapping      `json:"match"`
+}
+
+// FieldMapping tells where each song field is found: a dotted path into a
+// JSON document, where numeric segments index arrays, or a CSV column.
+// Fields left out are not taken from the provider.
+type FieldMapping struct {
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	Text        string `json:"text,omitempty"`
+	Link        string `json:"link,omitempty"`
+}
+
+// MatchMapping tells which keys of a catalogue record hold the group and the
+// song title the record is looked up by.
+type MatchMapping struct {
+	Group string `json:"group,omitempty"`
+	Song  string `json:"song,omitempty"`
+}
+
+// defaultFields is the shape of the song info API.
+var defaultFields = FieldMapping{
+	ReleaseDate: model.SongFieldReleaseDate,
+	Text:        model.SongFieldText,
+	Link:        model.SongFieldLink,
+}
+
+func LoadChainConfig(path string) (*ChainConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var conf ChainConfig
+	if err := decoder.Decode(&conf); err != nil {
+		return nil, fmt.Errorf("invalid metadata providers file %s: %w", path, err)
+	}
+	if err := conf.normalize(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("invalid metadata providers file %s: %w", path, err)
+	}
+	return &conf, nil
+}
+
+// normalize checks the providers and fills in defaults. Relative catalogue
+// paths are resolved against dir.
+func (c *ChainConfig) normalize(dir string) error {
+	if len(c.Providers) == 0 {
+		return fmt.Errorf("no providers")
+	}
+	names := make(map[string]bool, len(c.Providers))
+	for i := range c.Providers {
+		provider := &c.Providers[i]
+		if provider.Name == "" {
+			provider.Name = provider.Type
+		}
+		if names[provider.Name] {
+			return fmt.Errorf("provider name %q is used twice", provider.Name)
+		}
+		names[provider.Name] = true
+		if provider.Fields == (FieldMapping{}) {
+			provider.Fields = defaultFields
+		}
+
+		switch provider.Type {
+		case ProviderInfoAPI:
+			if provider.Fields != defaultFields {
+				return fmt.Errorf("provider %s: the song info API has a fixed shape, use an http provider to map fields", provider.Name)
+			}
+		case ProviderHTTP:
+			if provider.URL == "" {
+				return fmt.Errorf("provider %s: url is required", provider.Name)
+			}
+		case ProviderFile:
+			if provider.Path == "" {
+				return fmt.Errorf("provider %s: path is required", provider.Name)
+			}
+			if !filepath.IsAbs(provider.Path) {
+				provider.Path = filepath.Join(dir, provider.Path)
+			}
+			if provider.Format == "" {
+				provider.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(provider.Path)), ".")
+			}
+			if provider.Format != FormatJSON && provider.Format != FormatCSV {
+				return fmt.Errorf("provider %s: format must be %s or %s", provider.Name, FormatJSON, FormatCSV)
+			}
+			if provider.Match.Group == "" {
+				provider.Match.Group = "group"
+			}
+			if provider.Match.Song == "" {
+				provider.Match.Song = "song"
+			}
+		default:
+			return fmt.Errorf("provider %s: unknown type %q, expected %s, %s or %s", provider.Name, provider.Type, ProviderInfoAPI, ProviderHTTP, ProviderFile)
+		}
+	}
+	return nil
+}
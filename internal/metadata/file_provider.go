@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+type fileProvider struct {
+	name    string
+	fields  FieldMapping
+	records map[string]lookupFunc
+	lgr     *logger.Logger
+}
+
+// NewFileProvider loads a local catalogue: a JSON array of objects or a CSV
+// file with a header row. Records are matched by group and song ignoring
+// case. The file is read once, on start.
+func NewFileProvider(conf ProviderConfig, lgr *logger.Logger) (MetadataProvider, error) {
+	file, err := os.Open(conf.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []lookupFunc
+	if conf.Format == FormatCSV {
+		records, err = readCSVRecords(file)
+	} else {
+		records, err = readJSONRecords(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: cannot read %s: %w", conf.Name, conf.Path, err)
+	}
+
+	fp := &fileProvider{
+		name:    conf.Name,
+		fields:  conf.Fields,
+		records: make(map[string]lookupFunc, len(records)),
+		lgr:     lgr,
+	}
+	for _, record := range records {
+		key := catalogueKey(record(conf.Match.Group), record(conf.Match.Song))
+		if _, ok := fp.records[key]; !ok {
+			fp.records[key] = record
+		}
+	}
+	lgr.InfoLogger.Printf("Metadata provider %s loaded %d songs from %s\n", conf.Name, len(fp.records), conf.Path)
+	return fp, nil
+}
+
+func (fp *fileProvider) Name() string {
+	return fp.name
+}
+
+func (fp *fileProvider) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	record, ok := fp.records[catalogueKey(group, song)]
+	if !ok {
+		return nil, notFound(fp.name, group, song)
+	}
+	detail := mapDetail(fp.name, fp.fields, record, fp.lgr)
+	if detail == nil {
+		return nil, notFound(fp.name, group, song)
+	}
+	return detail, nil
+}
+
+func catalogueKey(group string, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
+
+func readJSONRecords(r io.Reader) ([]lookupFunc, error) {
+	var documents []interface{}
+	if err := json.NewDecoder(r).Decode(&documents); err != nil {
+		return nil, err
+	}
+	records := make([]lookupFunc, 0, len(documents))
+	for _, document := range documents {
+		document := document
+		records = append(records, func(path string) string {
+			return lookupPath(document, path)
+		})
+	}
+	return records, nil
+}
+
+func readCSVRecords(r io.Reader) ([]lookupFunc, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	records := make([]lookupFunc, 0, len(rows))
+	for _, row := range rows {
+		row := row
+		records = append(records, func(column string) string {
+			if i, ok := columns[column]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		})
+	}
+	return records, nil
+}
@@ -0,0 +1,111 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+const defaultHTTPTimeout = 5 * time.Second
+
+type httpProvider struct {
+	name       string
+	url        string
+	query      map[string]string
+	headers    map[string]string
+	fields     FieldMapping
+	httpClient *http.Client
+	lgr        *logger.Logger
+}
+
+// NewHTTPProvider asks an HTTP service that answers with JSON in any shape
+// the field mapping can describe.
+func NewHTTPProvider(conf ProviderConfig, lgr *logger.Logger) MetadataProvider {
+	timeout := time.Duration(conf.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	headers := make(map[string]string, len(conf.Headers))
+	for name, value := range conf.Headers {
+		headers[name] = os.ExpandEnv(value)
+	}
+	return &httpProvider{
+		name:       conf.Name,
+		url:        conf.URL,
+		query:      conf.Query,
+		headers:    headers,
+		fields:     conf.Fields,
+		httpClient: &http.Client{Timeout: timeout},
+		lgr:        lgr,
+	}
+}
+
+func (hp *httpProvider) Name() string {
+	return hp.name
+}
+
+func (hp *httpProvider) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hp.requestURL(group, song), nil)
+	if err != nil {
+		return nil, unavailable(hp.name, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range hp.headers {
+		req.Header.Set(name, value)
+	}
+	hp.lgr.DebugLogger.Printf("Calling metadata provider %s: %s\n", hp.name, req.URL.Redacted())
+
+	resp, err := hp.httpClient.Do(req)
+	if err != nil {
+		return nil, unavailable(hp.name, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, notFound(hp.name, group, song)
+	default:
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, unavailable(hp.name, &client.StatusError{StatusCode: resp.StatusCode})
+	}
+
+	var document interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, unavailable(hp.name, client.ErrInvalidResponse)
+	}
+	detail := mapDetail(hp.name, hp.fields, func(path string) string {
+		return lookupPath(document, path)
+	}, hp.lgr)
+	if detail == nil {
+		return nil, notFound(hp.name, group, song)
+	}
+	return detail, nil
+}
+
+// requestURL fills {group} and {song} into the URL and the query values.
+func (hp *httpProvider) requestURL(group string, song string) string {
+	requestURL := strings.NewReplacer("{group}", url.PathEscape(group), "{song}", url.PathEscape(song)).Replace(hp.url)
+	if len(hp.query) == 0 {
+		return requestURL
+	}
+	values := url.Values{}
+	replacer := strings.NewReplacer("{group}", group, "{song}", song)
+	for name, value := range hp.query {
+		values.Set(name, replacer.Replace(value))
+	}
+	separator := "?"
+	if strings.Contains(requestURL, "?") {
+		separator = "&"
+	}
+	return requestURL + separator + values.Encode()
+}
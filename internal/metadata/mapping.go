@@ -0,0 +1,78 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+// lookupFunc returns the value found under a key of the field mapping.
+type lookupFunc func(key string) string
+
+// mapDetail builds song details from the mapped fields. It returns nil when
+// none of them has a value. A release date that cannot be parsed is skipped.
+func mapDetail(provider string, fields FieldMapping, lookup lookupFunc, lgr *logger.Logger) *model.SongDetail {
+	var detail model.SongDetail
+	found := false
+	if fields.ReleaseDate != "" {
+		if value := lookup(fields.ReleaseDate); value != "" {
+			date, err := model.ParseDate(value)
+			if err != nil {
+				lgr.DebugLogger.Printf("Metadata provider %s returned an invalid release date: %v\n", provider, err)
+			} else {
+				detail.ReleaseDate, found = date, true
+			}
+		}
+	}
+	if fields.Text != "" {
+		detail.Text = lookup(fields.Text)
+		found = found || detail.Text != ""
+	}
+	if fields.Link != "" {
+		detail.Link = lookup(fields.Link)
+		found = found || detail.Link != ""
+	}
+	if !found {
+		return nil
+	}
+	return &detail
+}
+
+// lookupPath follows a dotted path through a decoded JSON document. Numeric
+// segments index arrays. Missing values and nulls are returned as "".
+func lookupPath(document interface{}, path string) string {
+	value := document
+	for _, segment := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			value = current[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(current) {
+				return ""
+			}
+			value = current[index]
+		default:
+			return ""
+		}
+	}
+	return stringValue(value)
+}
+
+func stringValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
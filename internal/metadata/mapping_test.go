@@ -0,0 +1,140 @@
+package metadata
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+)
+
+func TestLookupPath(t *testing.T) {
+	var document interface{}
+	err := json.Unmarshal([]byte(`{
+		"track": {"title": "Uprising", "released": "2009-09-07", "lyrics": null, "explicit": false},
+		"links": [{"url": "https://example.com/uprising"}, {"url": "https://example.com/live"}],
+		"plays": 1250000,
+		"tags": ["rock", "alternative"]
+	}`), &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "nested key", path: "track.released", want: "2009-09-07"},
+		{name: "array index", path: "links.1.url", want: "https://example.com/live"},
+		{name: "number", path: "plays", want: "1250000"},
+		{name: "bool", path: "track.explicit", want: "false"},
+		{name: "null", path: "track.lyrics", want: ""},
+		{name: "object", path: "links.0", want: `{"url":"https://example.com/uprising"}`},
+		{name: "array", path: "tags", want: `["rock","alternative"]`},
+		{name: "missing key", path: "track.album", want: ""},
+		{name: "index out of range", path: "links.2.url", want: ""},
+		{name: "negative index", path: "links.-1.url", want: ""},
+		{name: "key on an array", path: "links.url", want: ""},
+		{name: "path through a string", path: "track.title.length", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lookupPath(document, tt.path); got != tt.want {
+				t.Errorf("lookupPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "nil", value: nil, want: ""},
+		{name: "string", value: "Uprising", want: "Uprising"},
+		{name: "empty string", value: "", want: ""},
+		{name: "integer", value: float64(2009), want: "2009"},
+		{name: "fraction", value: 4.5, want: "4.5"},
+		{name: "large number", value: float64(1e21), want: "1000000000000000000000"},
+		{name: "true", value: true, want: "true"},
+		{name: "object", value: map[string]interface{}{"a": "b"}, want: `{"a":"b"}`},
+		{name: "array", value: []interface{}{"a", float64(1)}, want: `["a",1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stringValue(tt.value); got != tt.want {
+				t.Errorf("stringValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapDetail(t *testing.T) {
+	released := model.NewDate(time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC))
+	record := map[string]string{
+		"released":   "2009-09-07",
+		"legacy":     "07.09.2009",
+		"invalid":    "next autumn",
+		"lyrics":     "Paranoia is in bloom",
+		"url":        "https://example.com/uprising",
+		"blank_text": "",
+	}
+	lookup := func(key string) string { return record[key] }
+
+	tests := []struct {
+		name   string
+		fields FieldMapping
+		want   *model.SongDetail
+	}{
+		{
+			name:   "all fields",
+			fields: FieldMapping{ReleaseDate: "released", Text: "lyrics", Link: "url"},
+			want:   &model.SongDetail{ReleaseDate: released, Text: "Paranoia is in bloom", Link: "https://example.com/uprising"},
+		},
+		{
+			name:   "legacy date layout",
+			fields: FieldMapping{ReleaseDate: "legacy"},
+			want:   &model.SongDetail{ReleaseDate: released},
+		},
+		{
+			name:   "invalid date is skipped",
+			fields: FieldMapping{ReleaseDate: "invalid", Link: "url"},
+			want:   &model.SongDetail{Link: "https://example.com/uprising"},
+		},
+		{
+			name:   "unmapped fields stay empty",
+			fields: FieldMapping{Text: "lyrics"},
+			want:   &model.SongDetail{Text: "Paranoia is in bloom"},
+		},
+		{
+			name:   "only an invalid date",
+			fields: FieldMapping{ReleaseDate: "invalid"},
+			want:   nil,
+		},
+		{
+			name:   "only empty values",
+			fields: FieldMapping{Text: "blank_text", Link: "missing"},
+			want:   nil,
+		},
+		{
+			name:   "nothing mapped",
+			fields: FieldMapping{},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapDetail("catalogue", tt.fields, lookup, discardLogger())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mapDetail() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
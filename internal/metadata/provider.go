@@ -0,0 +1,51 @@
+// Package metadata looks song details up in an ordered chain of providers,
+// such as the song info API, a local catalogue file or another HTTP service.
+package metadata
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+)
+
+// MetadataProvider finds song details in one source. Like
+// client.MusicInfoClient it fails with ErrUpstreamNotFound wrapping
+// client.ErrNotFound when the source has no data for the song, and with
+// ErrUpstreamUnavailable when the source cannot be asked.
+type MetadataProvider interface {
+	Name() string
+	GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error)
+}
+
+type infoAPIProvider struct {
+	name       string
+	infoClient client.MusicInfoClient
+}
+
+// NewInfoAPIProvider wraps the song info API client, which answers in the
+// shape of model.SongDetail.
+func NewInfoAPIProvider(name string, infoClient client.MusicInfoClient) MetadataProvider {
+	return &infoAPIProvider{
+		name:       name,
+		infoClient: infoClient,
+	}
+}
+
+func (ip *infoAPIProvider) Name() string {
+	return ip.name
+}
+
+func (ip *infoAPIProvider) GetSongDetail(ctx context.Context, group string, song string) (*model.SongDetail, error) {
+	return ip.infoClient.GetSongDetail(ctx, group, song)
+}
+
+func notFound(provider string, group string, song string) error {
+	return apperrors.Wrap(apperrors.ErrUpstreamNotFound, fmt.Sprintf("%s has no data for %s - %s", provider, group, song), client.ErrNotFound)
+}
+
+func unavailable(provider string, err error) error {
+	return apperrors.Wrap(apperrors.ErrUpstreamUnavailable, fmt.Sprintf("%s is unavailable", provider), err)
+}
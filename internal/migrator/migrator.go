@@ -87,3 +87,42 @@ func (m *Migrator) RollbackMigrations(db *sql.DB, lgr *logger.Logger) error {
 	lgr.InfoLogger.Println("Migrations applied successfully")
 	return nil
 }
+
+// ReportReleaseDateBackfill logs the songs whose text release date could not
+// be converted to a DATE by migration 6. Their release_date is left NULL.
+func ReportReleaseDateBackfill(db *sql.DB, lgr *logger.Logger) error {
+	var table sql.NullString
+	if err := db.QueryRow(`SELECT to_regclass('release_date_backfill_failures')::text`).Scan(&table); err != nil {
+		return fmt.Errorf("unable to look up backfill report: %v", err)
+	}
+	if !table.Valid {
+		return nil
+	}
+
+	rows, err := db.Query(`SELECT song_id, raw_value FROM release_date_backfill_failures ORDER BY song_id`)
+	if err != nil {
+		return fmt.Errorf("unable to read backfill report: %v", err)
+	}
+	defer rows.Close()
+
+	failures := 0
+	for rows.Next() {
+		var songId int
+		var rawValue string
+		if err := rows.Scan(&songId, &rawValue); err != nil {
+			return fmt.Errorf("unable to read backfill report: %v", err)
+		}
+		lgr.ErrorLogger.Printf("Release date of song %d could not be parsed: %q\n", songId, rawValue)
+		failures++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("unable to read backfill report: %v", err)
+	}
+
+	if failures == 0 {
+		lgr.InfoLogger.Println("All release dates were converted successfully")
+	} else {
+		lgr.InfoLogger.Printf("%d release dates could not be converted, see table release_date_backfill_failures\n", failures)
+	}
+	return nil
+}
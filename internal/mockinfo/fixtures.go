@@ -0,0 +1,67 @@
+package mockinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+)
+
+// contractDateLayout is the release date layout of the song info service.
+const contractDateLayout = "02.01.2006"
+
+// Fixture is a song the mock knows. Group and Song are matched ignoring case.
+type Fixture struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// songDetail is the body of a successful /info response.
+type songDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// LoadFixtures reads a JSON array of fixtures. Release dates may be given
+// in any layout model.ParseDate accepts and are served as DD.MM.YYYY.
+func LoadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("invalid fixtures file %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(fixtures))
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		if strings.TrimSpace(fixture.Group) == "" || strings.TrimSpace(fixture.Song) == "" {
+			return nil, fmt.Errorf("fixture %d: group and song are required", i)
+		}
+		key := fixtureKey(fixture.Group, fixture.Song)
+		if seen[key] {
+			return nil, fmt.Errorf("fixture %d: %s - %s is listed twice", i, fixture.Group, fixture.Song)
+		}
+		seen[key] = true
+		if fixture.ReleaseDate != "" {
+			date, err := model.ParseDate(fixture.ReleaseDate)
+			if err != nil {
+				return nil, fmt.Errorf("fixture %d: %w", i, err)
+			}
+			fixture.ReleaseDate = date.Time().Format(contractDateLayout)
+		}
+	}
+	return fixtures, nil
+}
+
+func fixtureKey(group string, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
@@ -0,0 +1,100 @@
+package mockinfo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Fixture
+		wantErr string
+	}{
+		{
+			name: "dates are normalized",
+			content: `[
+				{"group": "Muse", "song": "Uprising", "releaseDate": "2009-09-07", "text": "Paranoia is in bloom", "link": "https://example.com/uprising"},
+				{"group": "Muse", "song": "Starlight", "releaseDate": "03.09.2006"},
+				{"group": "Muse", "song": "Undated"}
+			]`,
+			want: []Fixture{
+				{Group: "Muse", Song: "Uprising", ReleaseDate: "07.09.2009", Text: "Paranoia is in bloom", Link: "https://example.com/uprising"},
+				{Group: "Muse", Song: "Starlight", ReleaseDate: "03.09.2006"},
+				{Group: "Muse", Song: "Undated"},
+			},
+		},
+		{
+			name:    "empty list",
+			content: `[]`,
+			want:    []Fixture{},
+		},
+		{
+			name:    "duplicates ignoring case",
+			content: `[{"group": "Muse", "song": "Uprising"}, {"group": " muse", "song": "UPRISING "}]`,
+			wantErr: "fixture 1:  muse - UPRISING  is listed twice",
+		},
+		{
+			name:    "missing song",
+			content: `[{"group": "Muse", "song": " "}]`,
+			wantErr: "fixture 0: group and song are required",
+		},
+		{
+			name:    "invalid date",
+			content: `[{"group": "Muse", "song": "Uprising", "releaseDate": "autumn 2009"}]`,
+			wantErr: "fixture 0: invalid date",
+		},
+		{
+			name:    "malformed file",
+			content: `[{"group": "Muse", "song": "Uprising"`,
+			wantErr: "invalid fixtures file",
+		},
+		{
+			name:    "not a list",
+			content: `{"group": "Muse", "song": "Uprising"}`,
+			wantErr: "invalid fixtures file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixtures.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadFixtures(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFixtures() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFixturesBundled(t *testing.T) {
+	fixtures, err := LoadFixtures("../../config/mockinfo_fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Error("the bundled fixtures file is empty")
+	}
+}
+
+func TestLoadFixturesMissingFile(t *testing.T) {
+	if _, err := LoadFixtures(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("error = %v, want a not exist error", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package mockinfo is a stand-in for the song info service that serves
+// /info from fixtures and can simulate latency, failures and missing songs.
+package mockinfo
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+type server struct {
+	songs map[string]songDetail
+	conf  config.MockInfoConfig
+	mu    sync.Mutex
+	rnd   *rand.Rand
+	lgr   *logger.Logger
+}
+
+// NewServer serves the fixtures loaded by LoadFixtures. MOCKINFO_SEED makes
+// the simulated latency and failures repeatable; 0 seeds from the clock.
+func NewServer(fixtures []Fixture, conf config.MockInfoConfig, lgr *logger.Logger) *fiber.App {
+	songs := make(map[string]songDetail, len(fixtures))
+	for _, fixture := range fixtures {
+		songs[fixtureKey(fixture.Group, fixture.Song)] = songDetail{ReleaseDate: fixture.ReleaseDate, Text: fixture.Text, Link: fixture.Link}
+	}
+	seed := int64(conf.MOCKINFO_SEED)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := &server{
+		songs: songs,
+		conf:  conf,
+		rnd:   rand.New(rand.NewSource(seed)),
+		lgr:   lgr,
+	}
+
+	app := fiber.New()
+	app.Get("/info", s.getInfo)
+	app.Get("/health", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+// getInfo implements GET /info?group=&song=: 200 with the song detail, 400
+// without group or song, 404 for unknown songs and 500 for simulated errors.
+func (s *server) getInfo(c *fiber.Ctx) error {
+	group, song := c.Query("group"), c.Query("song")
+	if group == "" || song == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "group and song are required"})
+	}
+
+	delay, fail, missing := s.roll()
+	time.Sleep(delay)
+	if fail {
+		s.lgr.DebugLogger.Printf("Simulating an error for %s - %s\n", group, song)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "simulated failure"})
+	}
+
+	detail, ok := s.songs[fixtureKey(group, song)]
+	if !ok || missing {
+		s.lgr.DebugLogger.Printf("No song info for %s - %s, simulated: %t\n", group, song, ok)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "song not found"})
+	}
+	return c.JSON(detail)
+}
+
+// roll draws the latency and the simulated outcome of one request.
+func (s *server) roll() (time.Duration, bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delay := time.Duration(s.conf.MOCKINFO_LATENCY_MS) * time.Millisecond
+	if s.conf.MOCKINFO_LATENCY_JITTER_MS > 0 {
+		delay += time.Duration(s.rnd.Intn(s.conf.MOCKINFO_LATENCY_JITTER_MS+1)) * time.Millisecond
+	}
+	fail := s.rnd.Float64() < s.conf.MOCKINFO_ERROR_RATE
+	missing := s.rnd.Float64() < s.conf.MOCKINFO_NOT_FOUND_RATE
+	return delay, fail, missing
+}
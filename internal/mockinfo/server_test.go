@@ -0,0 +1,133 @@
+package mockinfo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+var testFixtures = []Fixture{
+	{Group: "Muse", Song: "Uprising", ReleaseDate: "07.09.2009", Text: "Paranoia is in bloom", Link: "https://example.com/uprising"},
+	{Group: "AC/DC", Song: "Highway to Hell", Text: "Living easy, living free"},
+}
+
+func discardLogger() *logger.Logger {
+	return &logger.Logger{
+		InfoLogger:  log.New(io.Discard, "", 0),
+		DebugLogger: log.New(io.Discard, "", 0),
+		ErrorLogger: log.New(io.Discard, "", 0),
+	}
+}
+
+func TestServerInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      url.Values
+		conf       config.MockInfoConfig
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "known song",
+			query:      url.Values{"group": {"Muse"}, "song": {"Uprising"}},
+			wantStatus: fiber.StatusOK,
+			wantBody:   `{"releaseDate":"07.09.2009","text":"Paranoia is in bloom","link":"https://example.com/uprising"}`,
+		},
+		{
+			name:       "case and escaping",
+			query:      url.Values{"group": {"ac/dc"}, "song": {"HIGHWAY TO HELL"}},
+			wantStatus: fiber.StatusOK,
+			wantBody:   `{"releaseDate":"","text":"Living easy, living free","link":""}`,
+		},
+		{
+			name:       "unknown song",
+			query:      url.Values{"group": {"Muse"}, "song": {"Unknown"}},
+			wantStatus: fiber.StatusNotFound,
+			wantBody:   `{"error":"song not found"}`,
+		},
+		{
+			name:       "missing group",
+			query:      url.Values{"song": {"Uprising"}},
+			wantStatus: fiber.StatusBadRequest,
+			wantBody:   `{"error":"group and song are required"}`,
+		},
+		{
+			name:       "missing song",
+			query:      url.Values{"group": {"Muse"}, "song": {""}},
+			wantStatus: fiber.StatusBadRequest,
+			wantBody:   `{"error":"group and song are required"}`,
+		},
+		{
+			name:       "simulated failure",
+			query:      url.Values{"group": {"Muse"}, "song": {"Uprising"}},
+			conf:       config.MockInfoConfig{MOCKINFO_ERROR_RATE: 1, MOCKINFO_SEED: 1},
+			wantStatus: fiber.StatusInternalServerError,
+			wantBody:   `{"error":"simulated failure"}`,
+		},
+		{
+			name:       "simulated missing song",
+			query:      url.Values{"group": {"Muse"}, "song": {"Uprising"}},
+			conf:       config.MockInfoConfig{MOCKINFO_NOT_FOUND_RATE: 1, MOCKINFO_SEED: 1},
+			wantStatus: fiber.StatusNotFound,
+			wantBody:   `{"error":"song not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := NewServer(testFixtures, tt.conf, discardLogger())
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/info?"+tt.query.Encode(), nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Errorf("GET /info?%s = %d %s, want %d %s", tt.query.Encode(), resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestServerWithClient checks that the song info client understands the mock.
+func TestServerWithClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := NewServer(testFixtures, config.MockInfoConfig{}, discardLogger())
+	go app.Listener(listener)
+	defer app.Shutdown()
+
+	infoClient := client.NewMusicInfoClient(config.MusicInfoConfig{EXTERNAL_API_URL: "http://" + listener.Addr().String()}, discardLogger())
+
+	detail, err := infoClient.GetSongDetail(context.Background(), "AC/DC", "Highway to Hell")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if detail.Text != "Living easy, living free" || !detail.ReleaseDate.IsZero() {
+		t.Errorf("detail = %+v", *detail)
+	}
+
+	detail, err = infoClient.GetSongDetail(context.Background(), "Muse", "Uprising")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if detail.ReleaseDate.String() != "2009-09-07" || detail.Link != "https://example.com/uprising" {
+		t.Errorf("detail = %+v", *detail)
+	}
+
+	if _, err := infoClient.GetSongDetail(context.Background(), "Muse", "Unknown"); !errors.Is(err, apperrors.ErrUpstreamNotFound) {
+		t.Errorf("error = %v, want %v", err, apperrors.ErrUpstreamNotFound)
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the ISO-8601 layout dates are exposed in. Input also accepts
+// the legacy 02.01.2006 layout used by the song info API.
+const DateLayout = "2006-01-02"
+
+var (
+	ErrInvalidDate = errors.New("invalid date")
+
+	dateLayouts = []string{DateLayout, "02.01.2006", time.RFC3339}
+)
+
+// Date is a calendar date without time of day. The zero value means the
+// date is unknown and is stored as NULL and marshalled as null.
+type Date struct {
+	value time.Time
+}
+
+func NewDate(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	return Date{value: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func ParseDate(value string) (Date, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return NewDate(t), nil
+		}
+	}
+	return Date{}, fmt.Errorf("%w %q, expected YYYY-MM-DD or DD.MM.YYYY", ErrInvalidDate, value)
+}
+
+func (d Date) Time() time.Time {
+	return d.value
+}
+
+func (d Date) IsZero() bool {
+	return d.value.IsZero()
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.value.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidDate, data)
+	}
+	if value == "" {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Date) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = NewDate(src)
+	case string:
+		parsed, err := ParseDate(src)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.value, nil
+}
@@ -0,0 +1,10 @@
+package model
+
+// IdempotentResponse is the response stored for an Idempotency-Key. A zero
+// StatusCode means the first request with the key is still running.
+type IdempotentResponse struct {
+	Fingerprint string
+	StatusCode  int
+	Headers     map[string]string
+	Body        []byte
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IfMatch is a parsed If-Match header. A nil *IfMatch means the header was
+// not sent and no precondition applies.
+type IfMatch struct {
+	Any      bool
+	Versions []int
+}
+
+// ParseIfMatch understands "*" and strong entity tags produced by
+// VersionETag. Weak or foreign tags are kept out, so they never match.
+func ParseIfMatch(header string) *IfMatch {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil
+	}
+	ifMatch := &IfMatch{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			ifMatch.Any = true
+			continue
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil {
+			ifMatch.Versions = append(ifMatch.Versions, version)
+		}
+	}
+	return ifMatch
+}
+
+func (m *IfMatch) Matches(version int) bool {
+	if m == nil || m.Any {
+		return true
+	}
+	for _, candidate := range m.Versions {
+		if candidate == version {
+			return true
+		}
+	}
+	return false
+}
+
+func VersionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "encoding/json"
+
+// Problem is an RFC 7807 problem details object, served as
+// application/problem+json for every failed request. Extensions are
+// serialized as additional top-level members.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	body, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+
+	var standard map[string]json.RawMessage
+	if err := json.Unmarshal(body, &standard); err != nil {
+		return nil, err
+	}
+	members := make(map[string]interface{}, len(p.Extensions)+len(standard))
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	for key, value := range standard {
+		members[key] = value
+	}
+	return json.Marshal(members)
+}
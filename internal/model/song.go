@@ -1,31 +1,97 @@
 package model
 
+import "time"
+
 type Song struct {
-	SoundId     int    `json:"sound_id,omitempty"`
-	Group       string `json:"group,omitempty"`
-	Song        string `json:"song,omitempty"`
-	ReleaseDate string `json:"releaseDate,omitempty"`
-	Text        string `json:"text,omitempty"`
-	Link        string `json:"link,omitempty"`
+	SoundId     int        `json:"sound_id,omitempty"`
+	Group       string     `json:"group,omitempty"`
+	Song        string     `json:"song,omitempty"`
+	ReleaseDate Date       `json:"releaseDate" swaggertype:"string" example:"2006-07-16"`
+	Text        string     `json:"text,omitempty"`
+	Link        string     `json:"link,omitempty"`
+	Version     int        `json:"version,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// PinnedFields are curated by editors and kept when the song is refreshed
+	// from the song info service.
+	PinnedFields []string `json:"pinnedFields,omitempty" example:"text"`
 }
 
+const (
+	SongModeEnrich           = "enrich"
+	SongModeManual           = "manual"
+	SongModeEnrichOrFallback = "enrich_or_fallback"
+)
+
+// SongRequest asks for a song to be created. Mode tells where its details
+// come from, enrich by default: enrich asks the song info service, manual
+// only uses the details of the request, and enrich_or_fallback uses them
+// when the service has no data or is down. The details of the request also
+// fill the fields the service leaves empty.
 type SongRequest struct {
-	Group string `json:"group"`
-	Song  string `json:"song"`
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	Mode        string `json:"mode,omitempty" enums:"enrich,manual,enrich_or_fallback"`
+	ReleaseDate Date   `json:"releaseDate" swaggertype:"string" example:"2006-07-16"`
+	Text        string `json:"text,omitempty"`
+	Link        string `json:"link,omitempty"`
 }
 
+// SongDetail is what the song info sources know about a song. Degraded is
+// set when a source that takes priority failed, so the details may be
+// incomplete or come from a less trusted source.
 type SongDetail struct {
-	ReleaseDate string `json:"releaseDate"`
+	ReleaseDate Date   `json:"releaseDate" swaggertype:"string" example:"16.07.2006"`
 	Text        string `json:"text"`
 	Link        string `json:"link"`
+	Degraded    bool   `json:"-"`
+}
+
+const (
+	SourceUpstream = "upstream"
+	SourceRequest  = "request"
+	SourceNone     = "none"
+)
+
+// SongSources tells where each detail of a created song came from.
+type SongSources struct {
+	ReleaseDate string `json:"releaseDate" example:"upstream"`
+	Text        string `json:"text" example:"upstream"`
+	Link        string `json:"link" example:"request"`
+}
+
+// NewSong fills the song from the song info details and falls back to the
+// details of the request for empty fields. detail is nil when the service
+// was not asked or had nothing.
+func NewSong(request SongRequest, detail *SongDetail) (Song, SongSources) {
+	if detail == nil {
+		detail = &SongDetail{}
+	}
+	song := Song{Group: request.Group, Song: request.Song}
+	var sources SongSources
+	song.ReleaseDate, sources.ReleaseDate = pickDetail(detail.ReleaseDate, request.ReleaseDate, Date.IsZero)
+	song.Text, sources.Text = pickDetail(detail.Text, request.Text, isEmpty)
+	song.Link, sources.Link = pickDetail(detail.Link, request.Link, isEmpty)
+	return song, sources
 }
 
-func NewSong(request SongRequest, detail SongDetail) Song {
-	return Song{
-		Group:       request.Group,
-		Song:        request.Song,
-		ReleaseDate: detail.ReleaseDate,
-		Text:        detail.Text,
-		Link:        detail.Link,
+func pickDetail[T any](upstream T, requested T, empty func(T) bool) (T, string) {
+	switch {
+	case !empty(upstream):
+		return upstream, SourceUpstream
+	case !empty(requested):
+		return requested, SourceRequest
 	}
+	return upstream, SourceNone
+}
+
+func isEmpty(value string) bool {
+	return value == ""
+}
+
+// SongInsertResult is the stored song together with the sources of its
+// details and the near-duplicates found while inserting it.
+type SongInsertResult struct {
+	Song
+	Sources *SongSources     `json:"sources,omitempty"`
+	Similar []SongSuggestion `json:"similar,omitempty"`
 }
@@ -0,0 +1,28 @@
+package model
+
+const (
+	BatchCreated       = "created"
+	BatchConflict      = "conflict"
+	BatchUpstreamError = "upstream_error"
+	BatchInvalid       = "invalid"
+	BatchAborted       = "aborted"
+	BatchError         = "error"
+)
+
+// SongBatchResult is the outcome for the item at Index of a batch. SoundId is
+// the created song or, for a conflict, the song that already exists. Sources
+// tells where the details of a created song came from.
+type SongBatchResult struct {
+	Index   int          `json:"index"`
+	Status  string       `json:"status" example:"created"`
+	SoundId int          `json:"sound_id,omitempty"`
+	Sources *SongSources `json:"sources,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+type SongBatchResponse struct {
+	Atomic  bool              `json:"atomic"`
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []SongBatchResult `json:"results"`
+}
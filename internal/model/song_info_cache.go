@@ -0,0 +1,17 @@
+package model
+
+// CachedSongDetail is a cached song info lookup. SongDetail is nil when the
+// song info service had no data for the song.
+type CachedSongDetail struct {
+	SongDetail *SongDetail
+}
+
+type SongInfoCacheStats struct {
+	MemoryHits     int64 `json:"memory_hits"`
+	PersistentHits int64 `json:"persistent_hits"`
+	NegativeHits   int64 `json:"negative_hits"`
+	Misses         int64 `json:"misses"`
+	Bypassed       int64 `json:"bypassed"`
+	Entries        int   `json:"entries"`
+	Persistent     bool  `json:"persistent"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobDead      = "dead"
+)
+
+// SongJob inserts a song in the background. It keeps the mode and details
+// of the SongRequest it was created from, and once it succeeded, where the
+// details of the stored song came from. A job that keeps failing ends up
+// dead and stays there until it is retried through the API.
+type SongJob struct {
+	ID              int64        `json:"id"`
+	Status          string       `json:"status" example:"queued"`
+	Group           string       `json:"group"`
+	Song            string       `json:"song"`
+	Mode            string       `json:"mode,omitempty" example:"enrich"`
+	ReleaseDate     Date         `json:"releaseDate" swaggertype:"string" example:"2006-07-16"`
+	Text            string       `json:"text,omitempty"`
+	Link            string       `json:"link,omitempty"`
+	RefreshExisting bool         `json:"refresh_existing"`
+	Editor          string       `json:"editor,omitempty"`
+	Attempts        int          `json:"attempts"`
+	MaxAttempts     int          `json:"max_attempts"`
+	LastError       string       `json:"last_error,omitempty"`
+	SoundId         int          `json:"sound_id,omitempty"`
+	Sources         *SongSources `json:"sources,omitempty"`
+	RunAt           time.Time    `json:"run_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+func (j SongJob) SongRequest() SongRequest {
+	return SongRequest{Group: j.Group, Song: j.Song, Mode: j.Mode, ReleaseDate: j.ReleaseDate, Text: j.Text, Link: j.Link}
+}
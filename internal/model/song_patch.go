@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PatchField records whether a JSON key was present and whether it was an
+// explicit null, which is what RFC 7396 merge patches need to tell "keep"
+// from "clear".
+type PatchField[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+type SongPatch struct {
+	Group       PatchField[string] `json:"group"`
+	Song        PatchField[string] `json:"song"`
+	ReleaseDate PatchField[Date]   `json:"releaseDate"`
+	Text        PatchField[string] `json:"text"`
+	Link        PatchField[string] `json:"link"`
+	// PinnedFields is optional even for PUT, absent keeps the current pins.
+	PinnedFields PatchField[[]string] `json:"pinnedFields"`
+}
+
+func (p SongPatch) MissingFields() []string {
+	fields := []struct {
+		name string
+		set  bool
+	}{
+		{"group", p.Group.Set},
+		{"song", p.Song.Set},
+		{"releaseDate", p.ReleaseDate.Set},
+		{"text", p.Text.Set},
+		{"link", p.Link.Set},
+	}
+	var missing []string
+	for _, field := range fields {
+		if !field.set {
+			missing = append(missing, field.name)
+		}
+	}
+	return missing
+}
+
+// Apply merges the patch into song. Absent keys keep the current value,
+// null clears it; group and song are required and cannot be cleared.
+func (p SongPatch) Apply(song Song) (Song, error) {
+	if p.Group.Set {
+		if p.Group.Null || strings.TrimSpace(p.Group.Value) == "" {
+			return song, fmt.Errorf("group cannot be empty")
+		}
+		song.Group = p.Group.Value
+	}
+	if p.Song.Set {
+		if p.Song.Null || strings.TrimSpace(p.Song.Value) == "" {
+			return song, fmt.Errorf("song cannot be empty")
+		}
+		song.Song = p.Song.Value
+	}
+	if p.ReleaseDate.Set {
+		song.ReleaseDate = p.ReleaseDate.Value
+	}
+	if p.Text.Set {
+		song.Text = p.Text.Value
+	}
+	if p.Link.Set {
+		song.Link = p.Link.Value
+	}
+	if p.PinnedFields.Set {
+		pinned, err := normalizePinnedFields(p.PinnedFields.Value)
+		if err != nil {
+			return song, err
+		}
+		song.PinnedFields = pinned
+	}
+	return song, nil
+}
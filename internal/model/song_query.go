@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+type SongFilter struct {
+	Group          string
+	Song           string
+	SongPrefix     bool
+	ReleasedAfter  *time.Time
+	ReleasedBefore *time.Time
+	HasText        *bool
+	LinkHost       string
+}
+
+type SongQuery struct {
+	Filter SongFilter
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+type SongCursor struct {
+	Sort     string `json:"s"`
+	Key      string `json:"k"`
+	ID       int    `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+type SongCursorQuery struct {
+	Filter SongFilter
+	Sort   string
+	After  *SongCursor
+	Limit  int
+}
+
+type SongKeysetPage struct {
+	Songs []Song
+	Next  *SongCursor
+	Prev  *SongCursor
+}
+
+type SongCursorPage struct {
+	Items      []Song  `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/linediff"
+)
+
+const (
+	SongFieldReleaseDate = "releaseDate"
+	SongFieldText        = "text"
+	SongFieldLink        = "link"
+)
+
+// EnrichedSongFields are the fields that come from the song info service.
+// Only these can be pinned.
+var EnrichedSongFields = []string{SongFieldReleaseDate, SongFieldText, SongFieldLink}
+
+// SongFieldChange is a field whose fresh value differs from the stored one.
+// Pinned changes and fields the service has no data for (Missing) are
+// reported but never applied. Lines is the line-level diff of the lyrics.
+type SongFieldChange struct {
+	Field   string          `json:"field" example:"text"`
+	Current string          `json:"current"`
+	Fresh   string          `json:"fresh"`
+	Pinned  bool            `json:"pinned"`
+	Missing bool            `json:"missing"`
+	Lines   []linediff.Line `json:"lines,omitempty"`
+}
+
+// Applicable tells whether refreshing the song applies the change.
+func (c SongFieldChange) Applicable() bool {
+	return !c.Pinned && !c.Missing
+}
+
+type SongRefresh struct {
+	SoundId int               `json:"sound_id"`
+	DryRun  bool              `json:"dry_run"`
+	Applied bool              `json:"applied"`
+	Changes []SongFieldChange `json:"changes"`
+	Song    Song              `json:"song"`
+}
+
+func (s Song) IsPinned(field string) bool {
+	for _, pinned := range s.PinnedFields {
+		if pinned == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplySongDetail merges fresh song details into song and lists the fields
+// that differ. Pinned fields are kept, and so are fields the details have no
+// value for: like NewSong, an empty value means no data, not a cleared one.
+func ApplySongDetail(song Song, detail SongDetail) (Song, []SongFieldChange) {
+	fields := []struct {
+		name           string
+		current, fresh string
+		apply          func(*Song)
+	}{
+		{SongFieldReleaseDate, song.ReleaseDate.String(), detail.ReleaseDate.String(), func(s *Song) { s.ReleaseDate = detail.ReleaseDate }},
+		{SongFieldText, song.Text, detail.Text, func(s *Song) { s.Text = detail.Text }},
+		{SongFieldLink, song.Link, detail.Link, func(s *Song) { s.Link = detail.Link }},
+	}
+	changes := make([]SongFieldChange, 0, len(fields))
+	refreshed := song
+	for _, field := range fields {
+		if field.current == field.fresh {
+			continue
+		}
+		change := SongFieldChange{Field: field.name, Current: field.current, Fresh: field.fresh, Pinned: song.IsPinned(field.name), Missing: field.fresh == ""}
+		if field.name == SongFieldText {
+			change.Lines = linediff.Diff(field.current, field.fresh)
+		}
+		if change.Applicable() {
+			field.apply(&refreshed)
+		}
+		changes = append(changes, change)
+	}
+	return refreshed, changes
+}
+
+// normalizePinnedFields checks the field names and drops duplicates.
+func normalizePinnedFields(fields []string) ([]string, error) {
+	normalized := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known := false
+		for _, enriched := range EnrichedSongFields {
+			known = known || field == enriched
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown pinned field %q, expected one of %s", field, strings.Join(EnrichedSongFields, ", "))
+		}
+		if !seen[field] {
+			seen[field] = true
+			normalized = append(normalized, field)
+		}
+	}
+	return normalized, nil
+}
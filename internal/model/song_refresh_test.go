@@ -0,0 +1,102 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplySongDetail(t *testing.T) {
+	stored := Song{
+		SoundId:     1,
+		Group:       "Muse",
+		Song:        "Uprising",
+		ReleaseDate: NewDate(time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)),
+		Text:        "Paranoia is in bloom",
+		Link:        "https://example.com/uprising",
+	}
+	fresh := SongDetail{
+		ReleaseDate: NewDate(time.Date(2009, 9, 14, 0, 0, 0, 0, time.UTC)),
+		Text:        "Paranoia is in bloom",
+		Link:        "https://example.com/uprising-live",
+	}
+
+	tests := []struct {
+		name        string
+		song        Song
+		detail      SongDetail
+		want        Song
+		wantChanges []SongFieldChange
+	}{
+		{
+			name:        "no changes",
+			song:        stored,
+			detail:      SongDetail{ReleaseDate: stored.ReleaseDate, Text: stored.Text, Link: stored.Link},
+			want:        stored,
+			wantChanges: []SongFieldChange{},
+		},
+		{
+			name:   "changed fields are applied",
+			song:   stored,
+			detail: fresh,
+			want: func() Song {
+				s := stored
+				s.ReleaseDate = fresh.ReleaseDate
+				s.Link = fresh.Link
+				return s
+			}(),
+			wantChanges: []SongFieldChange{
+				{Field: SongFieldReleaseDate, Current: "2009-09-07", Fresh: "2009-09-14"},
+				{Field: SongFieldLink, Current: stored.Link, Fresh: fresh.Link},
+			},
+		},
+		{
+			name: "pinned fields are kept",
+			song: func() Song {
+				s := stored
+				s.PinnedFields = []string{SongFieldLink}
+				return s
+			}(),
+			detail: SongDetail{ReleaseDate: stored.ReleaseDate, Text: stored.Text, Link: fresh.Link},
+			want: func() Song {
+				s := stored
+				s.PinnedFields = []string{SongFieldLink}
+				return s
+			}(),
+			wantChanges: []SongFieldChange{
+				{Field: SongFieldLink, Current: stored.Link, Fresh: fresh.Link, Pinned: true},
+			},
+		},
+		{
+			name:   "missing values are kept",
+			song:   stored,
+			detail: SongDetail{Text: stored.Text},
+			want:   stored,
+			wantChanges: []SongFieldChange{
+				{Field: SongFieldReleaseDate, Current: "2009-09-07", Missing: true},
+				{Field: SongFieldLink, Current: stored.Link, Missing: true},
+			},
+		},
+		{
+			name:   "empty stored values are filled",
+			song:   Song{SoundId: 1, Group: "Muse", Song: "Uprising"},
+			detail: SongDetail{Link: fresh.Link},
+			want:   Song{SoundId: 1, Group: "Muse", Song: "Uprising", Link: fresh.Link},
+			wantChanges: []SongFieldChange{
+				{Field: SongFieldLink, Fresh: fresh.Link},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changes := ApplySongDetail(tt.song, tt.detail)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("song = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("changes = %+v, want %+v", changes, tt.wantChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/linediff"
+)
+
+const (
+	RevisionImport  = "import"
+	RevisionInsert  = "insert"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionRevert  = "revert"
+)
+
+// SongRevision is a snapshot of a song right after a change. Revision is
+// the song version the change produced.
+type SongRevision struct {
+	SoundId     int       `json:"sound_id"`
+	Revision    int       `json:"revision"`
+	Action      string    `json:"action" example:"update"`
+	Group       string    `json:"group"`
+	Song        string    `json:"song"`
+	ReleaseDate Date      `json:"releaseDate" swaggertype:"string" example:"2006-07-16"`
+	Text        string    `json:"text,omitempty"`
+	Link        string    `json:"link,omitempty"`
+	Editor      string    `json:"editor,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type SongRevisionDiff struct {
+	SoundId      int             `json:"sound_id"`
+	FromRevision int             `json:"from_revision"`
+	ToRevision   int             `json:"to_revision"`
+	Lines        []linediff.Line `json:"lines"`
+}
@@ -0,0 +1,7 @@
+package model
+
+type SongSearchResult struct {
+	Song
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
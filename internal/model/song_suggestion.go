@@ -0,0 +1,10 @@
+package model
+
+type SongSuggestion struct {
+	SoundId    int     `json:"sound_id"`
+	Group      string  `json:"group"`
+	Song       string  `json:"song"`
+	GroupScore float32 `json:"group_score"`
+	SongScore  float32 `json:"song_score"`
+	Score      float32 `json:"score"`
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// NewDB opens the connection pool shared by all repositories.
+func NewDB(dsnStr string, lgr *logger.Logger) (*pgxpool.Pool, error) {
+	db, err := pgxpool.Connect(context.Background(), dsnStr)
+	if err != nil {
+		lgr.ErrorLogger.Println("Failed to connect to the database:", err)
+		return nil, err
+	}
+	return db, nil
+}
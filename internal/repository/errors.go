@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/jackc/pgconn"
+)
+
+const uniqueViolation = "23505"
+
+// BatchInsertError tells which song of a batch made the transaction fail.
+type BatchInsertError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchInsertError) Error() string {
+	return fmt.Sprintf("song %d of the batch: %v", e.Index, e.Err)
+}
+
+func (e *BatchInsertError) Unwrap() error {
+	return e.Err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// mapError translates PostgreSQL constraint and data errors into domain
+// errors. Anything else is returned untouched and ends up as a 500.
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch {
+	case pgErr.Code == uniqueViolation:
+		return apperrors.Wrap(apperrors.ErrConflict, "song already exists", err)
+	case strings.HasPrefix(pgErr.Code, "23"), strings.HasPrefix(pgErr.Code, "22"):
+		return apperrors.Wrap(apperrors.ErrValidation, "invalid song data", err)
+	}
+	return err
+}
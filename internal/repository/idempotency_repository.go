@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type IdempotencyRepository interface {
+	ReserveKey(ctx context.Context, key string, fingerprint string, lease time.Duration) (*model.IdempotentResponse, error)
+	SaveResponse(ctx context.Context, key string, response model.IdempotentResponse, ttl time.Duration) error
+	ReleaseKey(ctx context.Context, key string) error
+	PurgeExpiredKeys(ctx context.Context) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db  *pgxpool.Pool
+	lgr *logger.Logger
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool, lgr *logger.Logger) IdempotencyRepository {
+	return &idempotencyRepository{
+		db:  db,
+		lgr: lgr,
+	}
+}
+
+// maxReserveAttempts bounds how often ReserveKey starts over when the key
+// disappears between its two statements.
+const maxReserveAttempts = 3
+
+// ReserveKey claims the key for a new request for lease and returns nil. If
+// the key is already taken and not expired, the stored response is returned
+// instead; its StatusCode is zero while the first request is still running.
+// A request that never finishes, e.g. because the process died, frees the
+// key once the lease is over.
+func (ir *idempotencyRepository) ReserveKey(ctx context.Context, key string, fingerprint string, lease time.Duration) (*model.IdempotentResponse, error) {
+	for attempt := 1; ; attempt++ {
+		reserved, err := ir.insertKey(ctx, key, fingerprint, lease)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			ir.lgr.DebugLogger.Printf("Reserved idempotency key %q\n", key)
+			return nil, nil
+		}
+
+		response, err := ir.getResponse(ctx, key)
+		if errors.Is(err, pgx.ErrNoRows) && attempt < maxReserveAttempts {
+			// The first request released the key in the meantime.
+			continue
+		}
+		if err != nil {
+			ir.lgr.ErrorLogger.Printf("Error reading idempotency key %q: %v\n", key, err)
+			return nil, err
+		}
+		return response, nil
+	}
+}
+
+// insertKey reports false when the key is taken and not expired.
+func (ir *idempotencyRepository) insertKey(ctx context.Context, key string, fingerprint string, lease time.Duration) (bool, error) {
+	query := `INSERT INTO idempotency_keys (key, fingerprint, expires_at) VALUES ($1, $2, now() + $3 * interval '1 second')
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, headers = NULL, body = NULL,
+		    created_at = now(), expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < now()
+		RETURNING key;`
+	var reserved string
+	err := ir.db.QueryRow(ctx, query, key, fingerprint, lease.Seconds()).Scan(&reserved)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		ir.lgr.ErrorLogger.Printf("Error reserving idempotency key %q: %v\n", key, err)
+		return false, err
+	}
+	return true, nil
+}
+
+func (ir *idempotencyRepository) getResponse(ctx context.Context, key string) (*model.IdempotentResponse, error) {
+	var response model.IdempotentResponse
+	var statusCode *int
+	err := ir.db.QueryRow(ctx, `SELECT fingerprint, status_code, headers, body FROM idempotency_keys WHERE key = $1;`, key).
+		Scan(&response.Fingerprint, &statusCode, &response.Headers, &response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != nil {
+		response.StatusCode = *statusCode
+	}
+	return &response, nil
+}
+
+// SaveResponse stores the response and keeps the key for ttl from now on.
+func (ir *idempotencyRepository) SaveResponse(ctx context.Context, key string, response model.IdempotentResponse, ttl time.Duration) error {
+	query := `UPDATE idempotency_keys
+		SET status_code = $2, headers = $3, body = $4, expires_at = now() + $5 * interval '1 second'
+		WHERE key = $1;`
+	if _, err := ir.db.Exec(ctx, query, key, response.StatusCode, response.Headers, response.Body, ttl.Seconds()); err != nil {
+		ir.lgr.ErrorLogger.Printf("Error saving response for idempotency key %q: %v\n", key, err)
+		return err
+	}
+	return nil
+}
+
+// ReleaseKey forgets a key whose request failed, so it can be retried.
+func (ir *idempotencyRepository) ReleaseKey(ctx context.Context, key string) error {
+	if _, err := ir.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1;`, key); err != nil {
+		ir.lgr.ErrorLogger.Printf("Error releasing idempotency key %q: %v\n", key, err)
+		return err
+	}
+	return nil
+}
+
+func (ir *idempotencyRepository) PurgeExpiredKeys(ctx context.Context) (int64, error) {
+	tag, err := ir.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < now();`)
+	if err != nil {
+		ir.lgr.ErrorLogger.Println("Error purging expired idempotency keys:", err)
+		return 0, err
+	}
+	ir.lgr.InfoLogger.Printf("Purged %d expired idempotency keys.\n", tag.RowsAffected())
+	return tag.RowsAffected(), nil
+}
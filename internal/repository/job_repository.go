@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type JobRepository interface {
+	EnqueueSongJob(ctx context.Context, job model.SongJob) (*model.SongJob, error)
+	GetSongJob(ctx context.Context, jobId int64) (*model.SongJob, error)
+	ClaimSongJob(ctx context.Context, lease time.Duration) (*model.SongJob, error)
+	CompleteSongJob(ctx context.Context, jobId int64, songId int, sources *model.SongSources) error
+	FailSongJob(ctx context.Context, jobId int64, reason string, retryAt *time.Time) error
+	RetrySongJob(ctx context.Context, jobId int64) (*model.SongJob, error)
+}
+
+const jobColumns = `id, status, "group", song, mode, release_date, text, link, refresh_existing, editor, attempts, max_attempts, last_error, song_id, sources, run_at, created_at, updated_at`
+
+type jobRepository struct {
+	db  *pgxpool.Pool
+	lgr *logger.Logger
+}
+
+func NewJobRepository(db *pgxpool.Pool, lgr *logger.Logger) JobRepository {
+	return &jobRepository{
+		db:  db,
+		lgr: lgr,
+	}
+}
+
+func (jr *jobRepository) EnqueueSongJob(ctx context.Context, job model.SongJob) (*model.SongJob, error) {
+	query := fmt.Sprintf(`INSERT INTO song_jobs ("group", song, mode, release_date, text, link, refresh_existing, editor, max_attempts)
+		VALUES ($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, ''), $7, NULLIF($8, ''), $9) RETURNING %s;`, jobColumns)
+	queued, err := scanJob(jr.db.QueryRow(ctx, query, job.Group, job.Song, job.Mode, job.ReleaseDate, job.Text, job.Link,
+		job.RefreshExisting, job.Editor, job.MaxAttempts))
+	if err != nil {
+		jr.lgr.ErrorLogger.Printf("Error enqueueing job for %s - %s: %v\n", job.Group, job.Song, err)
+		return nil, err
+	}
+	jr.lgr.InfoLogger.Printf("Enqueued job %d for %s - %s.\n", queued.ID, job.Group, job.Song)
+	return queued, nil
+}
+
+func (jr *jobRepository) GetSongJob(ctx context.Context, jobId int64) (*model.SongJob, error) {
+	query := fmt.Sprintf(`SELECT %s FROM song_jobs WHERE id = $1;`, jobColumns)
+	job, err := scanJob(jr.db.QueryRow(ctx, query, jobId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, jobNotFound(jobId)
+	}
+	if err != nil {
+		jr.lgr.ErrorLogger.Printf("Error querying job %d: %v\n", jobId, err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// ClaimSongJob locks the next due job for lease and returns nil when there is
+// none. SKIP LOCKED lets several workers claim jobs at the same time, and a
+// running job whose lease ran out (its worker died) is claimed again, unless
+// it has no attempts left: then it is dead.
+func (jr *jobRepository) ClaimSongJob(ctx context.Context, lease time.Duration) (*model.SongJob, error) {
+	expired := `UPDATE song_jobs
+		SET status = 'dead', last_error = 'the job did not finish within its lease', locked_until = NULL, updated_at = now()
+		WHERE status = 'running' AND locked_until < now() AND attempts >= max_attempts;`
+	if tag, err := jr.db.Exec(ctx, expired); err != nil {
+		jr.lgr.ErrorLogger.Println("Error expiring jobs:", err)
+		return nil, err
+	} else if tag.RowsAffected() > 0 {
+		jr.lgr.ErrorLogger.Printf("%d jobs are dead after running out of their lease on the last attempt.\n", tag.RowsAffected())
+	}
+
+	query := fmt.Sprintf(`UPDATE song_jobs
+		SET status = 'running', attempts = attempts + 1, locked_until = now() + $1 * interval '1 second', updated_at = now()
+		WHERE id = (SELECT id FROM song_jobs
+		            WHERE status IN ('queued', 'running') AND run_at <= now() AND (locked_until IS NULL OR locked_until < now())
+		              AND attempts < max_attempts
+		            ORDER BY run_at, id
+		            FOR UPDATE SKIP LOCKED
+		            LIMIT 1)
+		RETURNING %s;`, jobColumns)
+	job, err := scanJob(jr.db.QueryRow(ctx, query, lease.Seconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		jr.lgr.ErrorLogger.Println("Error claiming a job:", err)
+		return nil, err
+	}
+	return job, nil
+}
+
+func (jr *jobRepository) CompleteSongJob(ctx context.Context, jobId int64, songId int, sources *model.SongSources) error {
+	query := `UPDATE song_jobs
+		SET status = 'succeeded', song_id = $2, sources = $3, last_error = NULL, locked_until = NULL, updated_at = now()
+		WHERE id = $1;`
+	if _, err := jr.db.Exec(ctx, query, jobId, songId, sources); err != nil {
+		jr.lgr.ErrorLogger.Printf("Error completing job %d: %v\n", jobId, err)
+		return err
+	}
+	jr.lgr.InfoLogger.Printf("Job %d succeeded with song ID %d.\n", jobId, songId)
+	return nil
+}
+
+// FailSongJob records a failed attempt. The job is queued again at retryAt,
+// or moved to the dead state when retryAt is nil.
+func (jr *jobRepository) FailSongJob(ctx context.Context, jobId int64, reason string, retryAt *time.Time) error {
+	query := `UPDATE song_jobs
+		SET status = CASE WHEN $3::timestamptz IS NULL THEN 'dead' ELSE 'queued' END,
+		    run_at = COALESCE($3, run_at), last_error = $2, locked_until = NULL, updated_at = now()
+		WHERE id = $1;`
+	if _, err := jr.db.Exec(ctx, query, jobId, reason, retryAt); err != nil {
+		jr.lgr.ErrorLogger.Printf("Error failing job %d: %v\n", jobId, err)
+		return err
+	}
+	return nil
+}
+
+// RetrySongJob queues a dead job again with a fresh set of attempts.
+func (jr *jobRepository) RetrySongJob(ctx context.Context, jobId int64) (*model.SongJob, error) {
+	query := fmt.Sprintf(`UPDATE song_jobs SET status = 'queued', attempts = 0, run_at = now(), updated_at = now()
+		WHERE id = $1 AND status = 'dead' RETURNING %s;`, jobColumns)
+	job, err := scanJob(jr.db.QueryRow(ctx, query, jobId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		current, err := jr.GetSongJob(ctx, jobId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, apperrors.New(apperrors.ErrConflict, fmt.Sprintf("job %d is %s, only dead jobs can be retried", jobId, current.Status))
+	}
+	if err != nil {
+		jr.lgr.ErrorLogger.Printf("Error retrying job %d: %v\n", jobId, err)
+		return nil, err
+	}
+	jr.lgr.InfoLogger.Printf("Job %d queued again.\n", jobId)
+	return job, nil
+}
+
+func jobNotFound(jobId int64) error {
+	return apperrors.New(apperrors.ErrNotFound, fmt.Sprintf("job with ID %d not found", jobId))
+}
+
+func scanJob(row pgx.Row) (*model.SongJob, error) {
+	var job model.SongJob
+	var mode, text, link, editor, lastError sql.NullString
+	var songId sql.NullInt32
+	err := row.Scan(&job.ID, &job.Status, &job.Group, &job.Song, &mode, &job.ReleaseDate, &text, &link, &job.RefreshExisting,
+		&editor, &job.Attempts, &job.MaxAttempts, &lastError, &songId, &job.Sources, &job.RunAt, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Mode, job.Text, job.Link = mode.String, text.String, link.String
+	job.Editor = editor.String
+	job.LastError = lastError.String
+	job.SoundId = int(songId.Int32)
+	return &job, nil
+}
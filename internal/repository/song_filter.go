@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+)
+
+const linkHostExpr = `lower(substring(link from '^[A-Za-z][A-Za-z0-9+.-]*://([^/:?#]+)'))`
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// buildSongFilter turns the filter into a WHERE clause that also hides songs
+// in the trash. Values are always passed as query arguments, numbered after
+// the ones already in args.
+func buildSongFilter(filter model.SongFilter, args []interface{}) (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Group != "" {
+		conditions = append(conditions, fmt.Sprintf(`lower("group") = lower(%s)`, addArg(filter.Group)))
+	}
+	if filter.Song != "" {
+		if filter.SongPrefix {
+			conditions = append(conditions, fmt.Sprintf(`lower(song) LIKE lower(%s) || '%%'`, addArg(likeEscaper.Replace(filter.Song))))
+		} else {
+			conditions = append(conditions, fmt.Sprintf(`lower(song) = lower(%s)`, addArg(filter.Song)))
+		}
+	}
+	if filter.ReleasedAfter != nil {
+		conditions = append(conditions, fmt.Sprintf(`release_date >= %s::date`, addArg(filter.ReleasedAfter.Format(model.DateLayout))))
+	}
+	if filter.ReleasedBefore != nil {
+		conditions = append(conditions, fmt.Sprintf(`release_date <= %s::date`, addArg(filter.ReleasedBefore.Format(model.DateLayout))))
+	}
+	if filter.HasText != nil {
+		if *filter.HasText {
+			conditions = append(conditions, `COALESCE(text, '') <> ''`)
+		} else {
+			conditions = append(conditions, `COALESCE(text, '') = ''`)
+		}
+	}
+	if filter.LinkHost != "" {
+		host := strings.ToLower(filter.LinkHost)
+		conditions = append(conditions, fmt.Sprintf(`(%s = %s OR %s LIKE %s)`, linkHostExpr, addArg(host), linkHostExpr, addArg("%."+likeEscaper.Replace(host))))
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
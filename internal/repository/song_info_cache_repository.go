@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// SongInfoCacheRepository is the persistent tier of the song info cache.
+type SongInfoCacheRepository interface {
+	GetSongDetail(ctx context.Context, group string, song string) (*model.CachedSongDetail, time.Time, error)
+	SaveSongDetail(ctx context.Context, group string, song string, cached model.CachedSongDetail, expiresAt time.Time) error
+	PurgeExpiredSongDetails(ctx context.Context) (int64, error)
+}
+
+type songInfoCacheRepository struct {
+	db  *pgxpool.Pool
+	lgr *logger.Logger
+}
+
+func NewSongInfoCacheRepository(db *pgxpool.Pool, lgr *logger.Logger) SongInfoCacheRepository {
+	return &songInfoCacheRepository{
+		db:  db,
+		lgr: lgr,
+	}
+}
+
+// GetSongDetail returns the cached lookup and when it expires, or nil when
+// nothing valid is cached.
+func (cr *songInfoCacheRepository) GetSongDetail(ctx context.Context, group string, song string) (*model.CachedSongDetail, time.Time, error) {
+	query := `SELECT found, release_date, text, link, expires_at FROM song_info_cache
+		WHERE "group" = $1 AND song = $2 AND expires_at > now();`
+	var found bool
+	var detail model.SongDetail
+	var text, link sql.NullString
+	var expiresAt time.Time
+	err := cr.db.QueryRow(ctx, query, group, song).Scan(&found, &detail.ReleaseDate, &text, &link, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		cr.lgr.ErrorLogger.Printf("Error reading cached song info for %s - %s: %v\n", group, song, err)
+		return nil, time.Time{}, err
+	}
+	if !found {
+		return &model.CachedSongDetail{}, expiresAt, nil
+	}
+	detail.Text = text.String
+	detail.Link = link.String
+	return &model.CachedSongDetail{SongDetail: &detail}, expiresAt, nil
+}
+
+func (cr *songInfoCacheRepository) SaveSongDetail(ctx context.Context, group string, song string, cached model.CachedSongDetail, expiresAt time.Time) error {
+	query := `INSERT INTO song_info_cache ("group", song, found, release_date, text, link, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7)
+		ON CONFLICT ("group", song) DO UPDATE
+		SET found = EXCLUDED.found, release_date = EXCLUDED.release_date, text = EXCLUDED.text,
+		    link = EXCLUDED.link, expires_at = EXCLUDED.expires_at;`
+	var detail model.SongDetail
+	if cached.SongDetail != nil {
+		detail = *cached.SongDetail
+	}
+	_, err := cr.db.Exec(ctx, query, group, song, cached.SongDetail != nil, detail.ReleaseDate, detail.Text, detail.Link, expiresAt)
+	if err != nil {
+		cr.lgr.ErrorLogger.Printf("Error caching song info for %s - %s: %v\n", group, song, err)
+		return err
+	}
+	return nil
+}
+
+func (cr *songInfoCacheRepository) PurgeExpiredSongDetails(ctx context.Context) (int64, error) {
+	tag, err := cr.db.Exec(ctx, `DELETE FROM song_info_cache WHERE expires_at <= now();`)
+	if err != nil {
+		cr.lgr.ErrorLogger.Println("Error purging expired song info:", err)
+		return 0, err
+	}
+	cr.lgr.InfoLogger.Printf("Purged %d expired song info entries.\n", tag.RowsAffected())
+	return tag.RowsAffected(), nil
+}
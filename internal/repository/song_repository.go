@@ -2,18 +2,65 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
 	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
 )
 
 type SongRepository interface {
-	GetSongs() ([]model.Song, error)
-	GetSong(songId int) (*model.Song, error)
-	InsertSong(song model.Song) error
-	UpdateSong(songId int, song model.Song) error
-	DeleteSong(songId int) error
+	GetSongs(ctx context.Context, query model.SongQuery) ([]model.Song, int, error)
+	GetSongsByCursor(ctx context.Context, query model.SongCursorQuery) (*model.SongKeysetPage, error)
+	SearchSongs(ctx context.Context, query string, limit int, offset int) ([]model.SongSearchResult, int, error)
+	SuggestSongs(ctx context.Context, query string, limit int) ([]model.SongSuggestion, error)
+	FindSimilarSongs(ctx context.Context, group string, song string, threshold float32, limit int) ([]model.SongSuggestion, error)
+	GetSong(ctx context.Context, songId int) (*model.Song, error)
+	FindSong(ctx context.Context, group string, song string) (*model.Song, error)
+	InsertSong(ctx context.Context, song model.Song) (*model.Song, error)
+	InsertSongs(ctx context.Context, songs []model.Song) ([]model.Song, error)
+	UpdateSong(ctx context.Context, songId int, song model.Song) (*model.Song, error)
+	RevertSong(ctx context.Context, songId int, song model.Song) (*model.Song, error)
+	DeleteSong(ctx context.Context, songId int, ifMatch *model.IfMatch) error
+	GetDeletedSongs(ctx context.Context, limit int, offset int) ([]model.Song, int, error)
+	RestoreSong(ctx context.Context, songId int) (*model.Song, error)
+	PurgeDeletedSongs(ctx context.Context, deletedBefore time.Time) (int64, error)
+	GetSongRevisions(ctx context.Context, songId int, limit int, offset int) ([]model.SongRevision, int, error)
+	GetSongRevision(ctx context.Context, songId int, revision int) (*model.SongRevision, error)
+}
+
+const songColumns = `id, "group", song, release_date, text, link, version, deleted_at, pinned_fields`
+
+const (
+	searchConfig  = "simple"
+	headlineOpts  = `StartSel=<b>, StopSel=</b>, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" ... "`
+	searchTsQuery = `websearch_to_tsquery('` + searchConfig + `', $1)`
+)
+
+type songSortKey struct {
+	expr    string
+	sqlType string
+}
+
+// songSortKeys maps API sort names to SQL expressions. Every expression is
+// backed by an index and is always followed by id so that the order is
+// stable between pages. sqlType is used to cast cursor keys.
+var songSortKeys = map[string]songSortKey{
+	"sound_id":     {expr: "id", sqlType: "integer"},
+	"text_length":  {expr: "COALESCE(length(text), 0)", sqlType: "integer"},
+	"song":         {expr: "lower(song)", sqlType: "text"},
+	"release_date": {expr: "COALESCE(release_date, '-infinity'::date)", sqlType: "date"},
+}
+
+func getSongSortKey(sort string) songSortKey {
+	if sortKey, ok := songSortKeys[sort]; ok {
+		return sortKey
+	}
+	return songSortKeys["sound_id"]
 }
 
 type songRepository struct {
@@ -21,87 +68,426 @@ type songRepository struct {
 	lgr *logger.Logger
 }
 
-func NewSongRepository(dsnStr string, lgr *logger.Logger) (SongRepository, error) {
-	dsn := fmt.Sprintf(dsnStr)
-	db, err := pgxpool.Connect(context.Background(), dsn)
-	if err != nil {
-		lgr.ErrorLogger.Println("Failed to connect to the database:", err)
-		return nil, err
-	}
+func NewSongRepository(db *pgxpool.Pool, lgr *logger.Logger) SongRepository {
 	lgr.InfoLogger.Println("SongRepository created successfully.")
 	return &songRepository{
 		db:  db,
 		lgr: lgr,
-	}, nil
+	}
 }
-func (sr *songRepository) GetSongs() ([]model.Song, error) {
-	sr.lgr.DebugLogger.Println("Getting all songs from the database.")
-	var songs []model.Song
-	query := `SELECT id, "group", song, release_date, text, link FROM songs`
-	rows, err := sr.db.Query(context.Background(), query)
+func (sr *songRepository) GetSongs(ctx context.Context, query model.SongQuery) ([]model.Song, int, error) {
+	sortKey := getSongSortKey(query.Sort)
+	sr.lgr.DebugLogger.Printf("Getting songs from the database: %+v\n", query)
+
+	where, args := buildSongFilter(query.Filter, nil)
+
+	var total int
+	if err := sr.db.QueryRow(ctx, `SELECT count(*) FROM songs`+where, args...).Scan(&total); err != nil {
+		sr.lgr.ErrorLogger.Println("Error counting songs:", err)
+		return nil, 0, err
+	}
+
+	songs := make([]model.Song, 0)
+	args = append(args, query.Limit, query.Offset)
+	sqlQuery := fmt.Sprintf(`SELECT %s FROM songs%s ORDER BY %s, id LIMIT $%d OFFSET $%d`, songColumns, where, sortKey.expr, len(args)-1, len(args))
+	rows, err := sr.db.Query(ctx, sqlQuery, args...)
 	if err != nil {
 		sr.lgr.ErrorLogger.Println("Error querying songs:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			sr.lgr.ErrorLogger.Println("Error scanning song row:", err)
+			return nil, 0, err
+		}
+		songs = append(songs, *song)
+	}
+	if rows.Err() != nil {
+		sr.lgr.ErrorLogger.Println("Row iteration error:", rows.Err())
+		return nil, 0, rows.Err()
+	}
+	sr.lgr.InfoLogger.Printf("Retrieved %d of %d songs from the database.\n", len(songs), total)
+	return songs, total, nil
+}
+func (sr *songRepository) GetSongsByCursor(ctx context.Context, query model.SongCursorQuery) (*model.SongKeysetPage, error) {
+	sortKey := getSongSortKey(query.Sort)
+	sr.lgr.DebugLogger.Printf("Getting songs from the database by cursor: %+v\n", query)
+
+	where, args := buildSongFilter(query.Filter, nil)
+	backward := query.After != nil && query.After.Backward
+	direction, comparison := "ASC", ">"
+	if backward {
+		direction, comparison = "DESC", "<"
+	}
+	if query.After != nil {
+		args = append(args, query.After.Key, query.After.ID)
+		where += fmt.Sprintf(" AND (%s, id) %s ($%d::%s, $%d)", sortKey.expr, comparison, len(args)-1, sortKey.sqlType, len(args))
+	}
+	args = append(args, query.Limit+1)
+	sqlQuery := fmt.Sprintf(`SELECT %s, (%s)::text FROM songs%s ORDER BY %s %s, id %s LIMIT $%d`,
+		songColumns, sortKey.expr, where, sortKey.expr, direction, direction, len(args))
+
+	rows, err := sr.db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		sr.lgr.ErrorLogger.Println("Error querying songs by cursor:", err)
 		return nil, err
 	}
 	defer rows.Close()
+	songs := make([]model.Song, 0)
+	keys := make([]string, 0)
 	for rows.Next() {
-		var song model.Song
-		err := rows.Scan(&song.SoundId, &song.Group, &song.Song, &song.ReleaseDate, &song.Text, &song.Link)
+		var key string
+		song, err := scanSong(rows, &key)
 		if err != nil {
 			sr.lgr.ErrorLogger.Println("Error scanning song row:", err)
 			return nil, err
 		}
-		songs = append(songs, song)
+		songs = append(songs, *song)
+		keys = append(keys, key)
 	}
 	if rows.Err() != nil {
 		sr.lgr.ErrorLogger.Println("Row iteration error:", rows.Err())
 		return nil, rows.Err()
 	}
-	sr.lgr.InfoLogger.Printf("Retrieved %d songs from the database.\n", len(songs))
-	return songs, nil
+
+	hasMore := len(songs) > query.Limit
+	if hasMore {
+		songs, keys = songs[:query.Limit], keys[:query.Limit]
+	}
+	if backward {
+		for i, j := 0, len(songs)-1; i < j; i, j = i+1, j-1 {
+			songs[i], songs[j] = songs[j], songs[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	page := &model.SongKeysetPage{Songs: songs}
+	if len(songs) > 0 {
+		first := &model.SongCursor{Sort: query.Sort, Key: keys[0], ID: songs[0].SoundId, Backward: true}
+		last := &model.SongCursor{Sort: query.Sort, Key: keys[len(keys)-1], ID: songs[len(songs)-1].SoundId}
+		if backward {
+			page.Next = last
+			if hasMore {
+				page.Prev = first
+			}
+		} else {
+			if hasMore {
+				page.Next = last
+			}
+			if query.After != nil {
+				page.Prev = first
+			}
+		}
+	}
+	sr.lgr.InfoLogger.Printf("Retrieved %d songs from the database by cursor.\n", len(songs))
+	return page, nil
 }
-func (sr *songRepository) GetSong(songId int) (*model.Song, error) {
+func (sr *songRepository) SearchSongs(ctx context.Context, query string, limit int, offset int) ([]model.SongSearchResult, int, error) {
+	sr.lgr.DebugLogger.Printf("Searching songs for %q, limit: %d, offset: %d\n", query, limit, offset)
 
-	var song model.Song
-	query := `SELECT id, "group", song, release_date, text, link FROM songs WHERE id = $1;`
-	err := sr.db.QueryRow(context.Background(), query, songId).Scan(&song.SoundId, &song.Group, &song.Song, &song.ReleaseDate, &song.Text, &song.Link)
+	var total int
+	countQuery := `SELECT count(*) FROM songs WHERE deleted_at IS NULL AND search_vector @@ ` + searchTsQuery
+	if err := sr.db.QueryRow(ctx, countQuery, query).Scan(&total); err != nil {
+		sr.lgr.ErrorLogger.Println("Error counting search results:", err)
+		return nil, 0, err
+	}
+
+	sqlQuery := fmt.Sprintf(`SELECT %s, ts_rank(search_vector, q.query) AS rank,
+		ts_headline('%s', COALESCE(text, ''), q.query, '%s')
+		FROM songs, %s AS q(query)
+		WHERE deleted_at IS NULL AND search_vector @@ q.query
+		ORDER BY rank DESC, id
+		LIMIT $2 OFFSET $3`, songColumns, searchConfig, headlineOpts, searchTsQuery)
+	rows, err := sr.db.Query(ctx, sqlQuery, query, limit, offset)
+	if err != nil {
+		sr.lgr.ErrorLogger.Println("Error searching songs:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+	results := make([]model.SongSearchResult, 0)
+	for rows.Next() {
+		var result model.SongSearchResult
+		song, err := scanSong(rows, &result.Rank, &result.Headline)
+		if err != nil {
+			sr.lgr.ErrorLogger.Println("Error scanning search result row:", err)
+			return nil, 0, err
+		}
+		result.Song = *song
+		results = append(results, result)
+	}
+	if rows.Err() != nil {
+		sr.lgr.ErrorLogger.Println("Row iteration error:", rows.Err())
+		return nil, 0, rows.Err()
+	}
+	sr.lgr.InfoLogger.Printf("Found %d of %d songs for %q.\n", len(results), total, query)
+	return results, total, nil
+}
+func (sr *songRepository) GetSong(ctx context.Context, songId int) (*model.Song, error) {
+	query := fmt.Sprintf(`SELECT %s FROM songs WHERE id = $1 AND deleted_at IS NULL;`, songColumns)
+	song, err := scanSong(sr.db.QueryRow(ctx, query, songId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		sr.lgr.DebugLogger.Printf("Song with ID %d not found\n", songId)
+		return nil, songNotFound(songId)
+	}
 	if err != nil {
 		sr.lgr.ErrorLogger.Printf("Error querying song with ID %d: %v\n", songId, err)
 		return nil, err
 	}
 	sr.lgr.InfoLogger.Printf("Retrieved song with ID %d.\n", songId)
-	return &song, nil
+	return song, nil
+}
+
+// FindSong looks a song up by group and title the same way the unique index
+// compares them, ignoring case and songs in the trash.
+func (sr *songRepository) FindSong(ctx context.Context, group string, song string) (*model.Song, error) {
+	query := fmt.Sprintf(`SELECT %s FROM songs WHERE lower("group") = lower($1) AND lower(song) = lower($2) AND deleted_at IS NULL;`, songColumns)
+	found, err := scanSong(sr.db.QueryRow(ctx, query, group, song))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.New(apperrors.ErrNotFound, fmt.Sprintf("song %s - %s not found", group, song))
+	}
+	if err != nil {
+		sr.lgr.ErrorLogger.Printf("Error looking up song %s - %s: %v\n", group, song, err)
+		return nil, err
+	}
+	return found, nil
 }
-func (sr *songRepository) InsertSong(song model.Song) error {
+func (sr *songRepository) InsertSong(ctx context.Context, song model.Song) (*model.Song, error) {
 	sr.lgr.DebugLogger.Printf("Inserting song: %+v\n", song)
-	query := `INSERT INTO songs("group", song, release_date, text, link) VALUES ($1, $2, $3, $4, $5);`
-	_, err := sr.db.Exec(context.Background(), query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link)
+	var inserted *model.Song
+	err := sr.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var err error
+		inserted, err = insertSong(ctx, tx, song)
+		return err
+	})
 	if err != nil {
 		sr.lgr.ErrorLogger.Printf("Error inserting song %+v: %v\n", song, err)
-		return err
+		return nil, sr.insertError(ctx, song, err)
 	}
-	sr.lgr.InfoLogger.Printf("Inserted song with ID %d.\n", song.SoundId)
-	return nil
+	sr.lgr.InfoLogger.Printf("Inserted song with ID %d.\n", inserted.SoundId)
+	return inserted, nil
 }
-func (sr *songRepository) UpdateSong(songId int, song model.Song) error {
+
+// InsertSongs inserts all songs in one transaction. If one of them fails
+// nothing is stored and the error is a *BatchInsertError for that song.
+func (sr *songRepository) InsertSongs(ctx context.Context, songs []model.Song) ([]model.Song, error) {
+	sr.lgr.DebugLogger.Printf("Inserting %d songs in one transaction\n", len(songs))
+	inserted := make([]model.Song, 0, len(songs))
+	failed := -1
+	err := sr.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		for i, song := range songs {
+			stored, err := insertSong(ctx, tx, song)
+			if err != nil {
+				failed = i
+				return err
+			}
+			inserted = append(inserted, *stored)
+		}
+		return nil
+	})
+	if err != nil {
+		sr.lgr.ErrorLogger.Printf("Error inserting %d songs: %v\n", len(songs), err)
+		if failed < 0 {
+			return nil, err
+		}
+		return nil, &BatchInsertError{Index: failed, Err: sr.insertError(ctx, songs[failed], err)}
+	}
+	sr.lgr.InfoLogger.Printf("Inserted %d songs.\n", len(inserted))
+	return inserted, nil
+}
+
+func insertSong(ctx context.Context, tx pgx.Tx, song model.Song) (*model.Song, error) {
+	query := fmt.Sprintf(`INSERT INTO songs("group", song, release_date, text, link) VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, '')) RETURNING %s;`, songColumns)
+	inserted, err := scanSong(tx.QueryRow(ctx, query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link))
+	if err != nil {
+		return nil, err
+	}
+	return inserted, recordRevision(ctx, tx, inserted.SoundId, model.RevisionInsert)
+}
+
+// insertError reports a duplicate together with the ID of the song that is
+// already stored.
+func (sr *songRepository) insertError(ctx context.Context, song model.Song, err error) error {
+	if isUniqueViolation(err) {
+		if existing, findErr := sr.FindSong(ctx, song.Group, song.Song); findErr == nil {
+			return SongExistsError(existing)
+		}
+	}
+	return mapError(err)
+}
+
+// UpdateSong overwrites the song and bumps its version. When song.Version is
+// set the row is only written if it still has that version, which makes a
+// read-modify-write in the controller atomic.
+func (sr *songRepository) UpdateSong(ctx context.Context, songId int, song model.Song) (*model.Song, error) {
+	return sr.updateSong(ctx, songId, song, model.RevisionUpdate)
+}
+
+// RevertSong is UpdateSong recorded as a revert in the song history.
+func (sr *songRepository) RevertSong(ctx context.Context, songId int, song model.Song) (*model.Song, error) {
+	return sr.updateSong(ctx, songId, song, model.RevisionRevert)
+}
+
+func (sr *songRepository) updateSong(ctx context.Context, songId int, song model.Song, action string) (*model.Song, error) {
 	sr.lgr.DebugLogger.Printf("Updating song with ID %d: %+v\n", songId, song)
-	query := `UPDATE songs SET "group"=$1, song=$2, release_date=$3, text=$4, link=$5 WHERE id=$6;`
-	_, err := sr.db.Exec(context.Background(), query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, songId)
+	query := fmt.Sprintf(`UPDATE songs SET "group"=$1, song=$2, release_date=$3, text=NULLIF($4, ''), link=NULLIF($5, ''), pinned_fields=COALESCE($8::text[], '{}'), version=version+1
+		WHERE id=$6 AND deleted_at IS NULL AND ($7 = 0 OR version=$7) RETURNING %s;`, songColumns)
+	var updated *model.Song
+	err := sr.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var err error
+		updated, err = scanSong(tx.QueryRow(ctx, query, song.Group, song.Song, song.ReleaseDate, song.Text, song.Link, songId, song.Version, song.PinnedFields))
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, songId, action)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sr.versionMismatch(ctx, songId)
+	}
 	if err != nil {
 		sr.lgr.ErrorLogger.Printf("Error updating song with ID %d: %v\n", songId, err)
-		return err
+		return nil, mapError(err)
 	}
-	sr.lgr.InfoLogger.Printf("Updated song with ID %d.\n", songId)
-	return nil
+	sr.lgr.InfoLogger.Printf("Updated song with ID %d to version %d.\n", songId, updated.Version)
+	return updated, nil
 }
-func (sr *songRepository) DeleteSong(songId int) error {
-	sr.lgr.DebugLogger.Printf("Deleting song with ID %d from the database.\n", songId)
-	query := `DELETE FROM songs WHERE id=$1;`
-	_, err := sr.db.Exec(context.Background(), query, songId)
+func (sr *songRepository) DeleteSong(ctx context.Context, songId int, ifMatch *model.IfMatch) error {
+	sr.lgr.DebugLogger.Printf("Moving song with ID %d to the trash.\n", songId)
+	var versions []int32
+	checkVersion := ifMatch != nil && !ifMatch.Any
+	if checkVersion {
+		versions = make([]int32, 0, len(ifMatch.Versions))
+		for _, version := range ifMatch.Versions {
+			versions = append(versions, int32(version))
+		}
+	}
+	query := `UPDATE songs SET deleted_at=now(), version=version+1
+		WHERE id=$1 AND deleted_at IS NULL AND (NOT $2 OR version = ANY($3));`
+	err := sr.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, query, songId, checkVersion, versions)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return recordRevision(ctx, tx, songId, model.RevisionDelete)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sr.versionMismatch(ctx, songId)
+	}
 	if err != nil {
 		sr.lgr.ErrorLogger.Printf("Error deleting song with ID %d: %v\n", songId, err)
-		return err
+		return mapError(err)
 	}
-	sr.lgr.InfoLogger.Printf("Deleted song with ID %d.\n", songId)
+	sr.lgr.InfoLogger.Printf("Moved song with ID %d to the trash.\n", songId)
 	return nil
 }
+
+// versionMismatch explains why a conditional write touched no rows: either
+// the song is gone or it has a different version by now.
+func (sr *songRepository) versionMismatch(ctx context.Context, songId int) error {
+	var version int
+	err := sr.db.QueryRow(ctx, `SELECT version FROM songs WHERE id = $1 AND deleted_at IS NULL;`, songId).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		sr.lgr.DebugLogger.Printf("Song with ID %d not found\n", songId)
+		return songNotFound(songId)
+	}
+	if err != nil {
+		sr.lgr.ErrorLogger.Printf("Error querying version of song with ID %d: %v\n", songId, err)
+		return err
+	}
+	sr.lgr.DebugLogger.Printf("Song with ID %d has version %d, precondition failed\n", songId, version)
+	return apperrors.New(apperrors.ErrPreconditionFailed, fmt.Sprintf("song with ID %d was modified, current version is %d", songId, version))
+}
+
+func (sr *songRepository) GetDeletedSongs(ctx context.Context, limit int, offset int) ([]model.Song, int, error) {
+	sr.lgr.DebugLogger.Printf("Getting deleted songs, limit: %d, offset: %d\n", limit, offset)
+
+	var total int
+	if err := sr.db.QueryRow(ctx, `SELECT count(*) FROM songs WHERE deleted_at IS NOT NULL`).Scan(&total); err != nil {
+		sr.lgr.ErrorLogger.Println("Error counting deleted songs:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM songs WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id LIMIT $1 OFFSET $2`, songColumns)
+	rows, err := sr.db.Query(ctx, query, limit, offset)
+	if err != nil {
+		sr.lgr.ErrorLogger.Println("Error querying deleted songs:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+	songs := make([]model.Song, 0)
+	for rows.Next() {
+		song, err := scanSong(rows)
+		if err != nil {
+			sr.lgr.ErrorLogger.Println("Error scanning song row:", err)
+			return nil, 0, err
+		}
+		songs = append(songs, *song)
+	}
+	if rows.Err() != nil {
+		sr.lgr.ErrorLogger.Println("Row iteration error:", rows.Err())
+		return nil, 0, rows.Err()
+	}
+	sr.lgr.InfoLogger.Printf("Retrieved %d of %d deleted songs.\n", len(songs), total)
+	return songs, total, nil
+}
+func (sr *songRepository) RestoreSong(ctx context.Context, songId int) (*model.Song, error) {
+	sr.lgr.DebugLogger.Printf("Restoring song with ID %d from the trash.\n", songId)
+	query := fmt.Sprintf(`UPDATE songs SET deleted_at=NULL, version=version+1
+		WHERE id=$1 AND deleted_at IS NOT NULL RETURNING %s;`, songColumns)
+	var song *model.Song
+	err := sr.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		var err error
+		song, err = scanSong(tx.QueryRow(ctx, query, songId))
+		if err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, songId, model.RevisionRestore)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.New(apperrors.ErrNotFound, fmt.Sprintf("song with ID %d is not in the trash", songId))
+	}
+	if err != nil {
+		sr.lgr.ErrorLogger.Printf("Error restoring song with ID %d: %v\n", songId, err)
+		return nil, mapError(err)
+	}
+	sr.lgr.InfoLogger.Printf("Restored song with ID %d.\n", songId)
+	return song, nil
+}
+func (sr *songRepository) PurgeDeletedSongs(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tag, err := sr.db.Exec(ctx, `DELETE FROM songs WHERE deleted_at < $1;`, deletedBefore)
+	if err != nil {
+		sr.lgr.ErrorLogger.Println("Error purging deleted songs:", err)
+		return 0, err
+	}
+	sr.lgr.InfoLogger.Printf("Purged %d songs deleted before %s.\n", tag.RowsAffected(), deletedBefore.Format(time.RFC3339))
+	return tag.RowsAffected(), nil
+}
+
+func songNotFound(songId int) error {
+	return apperrors.New(apperrors.ErrNotFound, fmt.Sprintf("song with ID %d not found", songId))
+}
+
+// SongExistsError is the conflict reported for a duplicate song. The problem
+// details carry the ID of the song that already exists.
+func SongExistsError(song *model.Song) error {
+	return apperrors.New(apperrors.ErrConflict, fmt.Sprintf("song %s - %s already exists with ID %d", song.Group, song.Song, song.SoundId)).
+		WithExtension("sound_id", song.SoundId)
+}
+
+// scanSong reads songColumns followed by extra columns. Cleared text and
+// link are stored as NULL and come back as empty strings.
+func scanSong(row pgx.Row, extra ...interface{}) (*model.Song, error) {
+	var song model.Song
+	var text, link sql.NullString
+	dest := append([]interface{}{&song.SoundId, &song.Group, &song.Song, &song.ReleaseDate, &text, &link, &song.Version, &song.DeletedAt, &song.PinnedFields}, extra...)
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	song.Text, song.Link = text.String, link.String
+	return &song, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/audit"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/jackc/pgx/v4"
+)
+
+const revisionColumns = `song_id, revision, action, "group", song, release_date, text, link, editor, created_at`
+
+// recordRevision snapshots the song as it is inside tx, so the history is
+// written together with the change or not at all.
+func recordRevision(ctx context.Context, tx pgx.Tx, songId int, action string) error {
+	query := `INSERT INTO song_revisions (song_id, revision, action, "group", song, release_date, text, link, editor)
+		SELECT id, version, $2, "group", song, release_date, text, link, NULLIF($3, '')
+		FROM songs WHERE id = $1;`
+	_, err := tx.Exec(ctx, query, songId, action, audit.Editor(ctx))
+	return err
+}
+
+func (sr *songRepository) GetSongRevisions(ctx context.Context, songId int, limit int, offset int) ([]model.SongRevision, int, error) {
+	sr.lgr.DebugLogger.Printf("Getting revisions of song with ID %d, limit: %d, offset: %d\n", songId, limit, offset)
+
+	var total int
+	if err := sr.db.QueryRow(ctx, `SELECT count(*) FROM song_revisions WHERE song_id = $1`, songId).Scan(&total); err != nil {
+		sr.lgr.ErrorLogger.Println("Error counting song revisions:", err)
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, songNotFound(songId)
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM song_revisions WHERE song_id = $1 ORDER BY revision DESC LIMIT $2 OFFSET $3`, revisionColumns)
+	rows, err := sr.db.Query(ctx, query, songId, limit, offset)
+	if err != nil {
+		sr.lgr.ErrorLogger.Println("Error querying song revisions:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+	revisions := make([]model.SongRevision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			sr.lgr.ErrorLogger.Println("Error scanning revision row:", err)
+			return nil, 0, err
+		}
+		revisions = append(revisions, *revision)
+	}
+	if rows.Err() != nil {
+		sr.lgr.ErrorLogger.Println("Row iteration error:", rows.Err())
+		return nil, 0, rows.Err()
+	}
+	return revisions, total, nil
+}
+
+func (sr *songRepository) GetSongRevision(ctx context.Context, songId int, revision int) (*model.SongRevision, error) {
+	query := fmt.Sprintf(`SELECT %s FROM song_revisions WHERE song_id = $1 AND revision = $2;`, revisionColumns)
+	songRevision, err := scanRevision(sr.db.QueryRow(ctx, query, songId, revision))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.New(apperrors.ErrNotFound, fmt.Sprintf("revision %d of song with ID %d not found", revision, songId))
+	}
+	if err != nil {
+		sr.lgr.ErrorLogger.Printf("Error querying revision %d of song with ID %d: %v\n", revision, songId, err)
+		return nil, err
+	}
+	return songRevision, nil
+}
+
+func scanRevision(row pgx.Row) (*model.SongRevision, error) {
+	var revision model.SongRevision
+	var text, link, editor sql.NullString
+	err := row.Scan(&revision.SoundId, &revision.Revision, &revision.Action, &revision.Group, &revision.Song,
+		&revision.ReleaseDate, &text, &link, &editor, &revision.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	revision.Text = text.String
+	revision.Link = link.String
+	revision.Editor = editor.String
+	return &revision, nil
+}
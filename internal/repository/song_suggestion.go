@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/jackc/pgx/v4"
+)
+
+// Both queries rely on the pg_trgm % operator, so they can use the trigram
+// indexes from migration 5; similarity() then ranks the candidates.
+const (
+	suggestSongsQuery = `SELECT id, "group", song, group_score, song_score,
+		GREATEST(group_score, song_score, similarity("group" || ' ' || song, $1)) AS score
+		FROM (SELECT id, "group", song, similarity("group", $1) AS group_score, similarity(song, $1) AS song_score
+		      FROM songs
+		      WHERE deleted_at IS NULL AND ("group" % $1 OR song % $1)) AS candidates
+		ORDER BY score DESC, id
+		LIMIT $2`
+	similarSongsQuery = `SELECT id, "group", song, group_score, song_score, ((group_score + song_score) / 2)::real AS score
+		FROM (SELECT id, "group", song, similarity("group", $1) AS group_score, similarity(song, $2) AS song_score
+		      FROM songs
+		      WHERE deleted_at IS NULL AND "group" % $1 AND song % $2) AS candidates
+		WHERE (group_score + song_score) / 2 >= $3
+		ORDER BY score DESC, id
+		LIMIT $4`
+)
+
+func (sr *songRepository) SuggestSongs(ctx context.Context, query string, limit int) ([]model.SongSuggestion, error) {
+	sr.lgr.DebugLogger.Printf("Suggesting songs for %q, limit: %d\n", query, limit)
+	rows, err := sr.db.Query(ctx, suggestSongsQuery, query, limit)
+	if err != nil {
+		sr.lgr.ErrorLogger.Println("Error suggesting songs:", err)
+		return nil, err
+	}
+	return sr.scanSuggestions(rows)
+}
+
+func (sr *songRepository) FindSimilarSongs(ctx context.Context, group string, song string, threshold float32, limit int) ([]model.SongSuggestion, error) {
+	sr.lgr.DebugLogger.Printf("Looking for songs similar to %s - %s\n", group, song)
+	rows, err := sr.db.Query(ctx, similarSongsQuery, group, song, threshold, limit)
+	if err != nil {
+		sr.lgr.ErrorLogger.Println("Error looking for similar songs:", err)
+		return nil, err
+	}
+	return sr.scanSuggestions(rows)
+}
+
+func (sr *songRepository) scanSuggestions(rows pgx.Rows) ([]model.SongSuggestion, error) {
+	defer rows.Close()
+	suggestions := make([]model.SongSuggestion, 0)
+	for rows.Next() {
+		var suggestion model.SongSuggestion
+		err := rows.Scan(&suggestion.SoundId, &suggestion.Group, &suggestion.Song, &suggestion.GroupScore, &suggestion.SongScore, &suggestion.Score)
+		if err != nil {
+			sr.lgr.ErrorLogger.Println("Error scanning suggestion row:", err)
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if rows.Err() != nil {
+		sr.lgr.ErrorLogger.Println("Row iteration error:", rows.Err())
+		return nil, rows.Err()
+	}
+	return suggestions, nil
+}
@@ -3,20 +3,45 @@ package router
 import (
 	"fmt"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/handler"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/gofiber/swagger"
 )
 
-func NewFiberRouter(songHandler handler.SongHandler, port int) *fiber.App {
-	app := fiber.New()
+func NewFiberRouter(songHandler handler.SongHandler, jobHandler handler.JobHandler, cacheHandler handler.CacheHandler, idempotency fiber.Handler, port int, lgr *logger.Logger) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: handler.NewErrorHandler(lgr),
+	})
+	app.Use(requestid.New())
+	app.Use(handler.NewEditorMiddleware())
+	app.Use(recover.New(recover.Config{
+		EnableStackTrace:  true,
+		StackTraceHandler: handler.NewPanicHandler(lgr),
+	}))
 	app.Static("/docs", "./docs")
 	app.Get("/swagger/*", swagger.New(swagger.Config{ // custom
 		URL: fmt.Sprintf("http://localhost:%v/docs/swagger.json", port),
 	}))
 	app.Get("/songs", songHandler.GetSongs)
+	app.Get("/songs/search", songHandler.SearchSongs)
+	app.Get("/songs/suggest", songHandler.SuggestSongs)
+	app.Get("/songs/trash", songHandler.GetTrash)
+	app.Get("/songs/:song_id", songHandler.GetSong)
 	app.Get("/songs/:song_id/text", songHandler.GetSongText)
+	app.Get("/songs/:song_id/revisions", songHandler.GetSongRevisions)
+	app.Get("/songs/:song_id/revisions/:revision/diff", songHandler.DiffSongRevision)
 	app.Delete("/songs/:song_id", songHandler.DeleteSong)
 	app.Put("/songs/:song_id", songHandler.UpdateSong)
-	app.Post("/songs", songHandler.InsertSong)
+	app.Patch("/songs/:song_id", songHandler.PatchSong)
+	app.Post("/songs", idempotency, songHandler.InsertSong)
+	app.Post("/songs/batch", idempotency, songHandler.InsertSongs)
+	app.Post("/songs/:song_id/restore", songHandler.RestoreSong)
+	app.Post("/songs/:song_id/refresh", songHandler.RefreshSong)
+	app.Post("/songs/:song_id/revisions/:revision/revert", songHandler.RevertSong)
+	app.Get("/jobs/:job_id", jobHandler.GetJob)
+	app.Post("/jobs/:job_id/retry", jobHandler.RetryJob)
+	app.Get("/cache/song-info/stats", cacheHandler.GetSongInfoCacheStats)
 	return app
 }
@@ -3,18 +3,70 @@ package initialization
 import (
 	"errors"
 	"fmt"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/client"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/controller"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/handler"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/metadata"
 	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/worker"
 	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"time"
 )
 
-func InitializeComponentsSong(dsnStr string, lgr *logger.Logger) (handler.SongHandler, error) {
-	songRepo, err := repository.NewSongRepository(dsnStr, lgr)
+type Components struct {
+	SongHandler  handler.SongHandler
+	JobHandler   handler.JobHandler
+	CacheHandler handler.CacheHandler
+	Idempotency  fiber.Handler
+	Workers      []worker.Worker
+}
+
+func InitializeComponentsSong(dsnStr string, conf *config.Config, lgr *logger.Logger) (*Components, error) {
+	db, err := repository.NewDB(dsnStr, lgr)
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("DB connection has failed: %v", err))
 	}
-	songController := controller.NewSongController(songRepo, lgr)
-	userHandler := handler.NewSongHandler(songController, lgr)
-	return userHandler, nil
+	songRepo := repository.NewSongRepository(db, lgr)
+	idempotencyRepo := repository.NewIdempotencyRepository(db, lgr)
+	jobRepo := repository.NewJobRepository(db, lgr)
+	var infoCacheRepo repository.SongInfoCacheRepository
+	if conf.InfoCache.INFO_CACHE_PERSISTENT {
+		infoCacheRepo = repository.NewSongInfoCacheRepository(db, lgr)
+	}
+	providers, err := metadata.NewProviderChain(conf.Metadata.METADATA_PROVIDERS_FILE, client.NewMusicInfoClient(conf.MusicInfo, lgr), lgr)
+	if err != nil {
+		return nil, fmt.Errorf("metadata providers setup has failed: %w", err)
+	}
+	infoClient := client.NewCachedMusicInfoClient(providers, infoCacheRepo, conf.InfoCache, lgr)
+	songController := controller.NewSongController(songRepo, infoClient, conf.Batch, lgr)
+	jobController := controller.NewJobController(jobRepo, conf.Job.JOB_MAX_ATTEMPTS, lgr)
+	userHandler := handler.NewSongHandler(songController, jobController, lgr)
+	jobHandler := handler.NewJobHandler(jobController, lgr)
+	cacheHandler := handler.NewCacheHandler(infoClient, lgr)
+	idempotency := handler.NewIdempotencyMiddleware(idempotencyRepo,
+		time.Duration(conf.Idempotency.IDEMPOTENCY_LEASE_SECONDS)*time.Second,
+		time.Duration(conf.Idempotency.IDEMPOTENCY_KEY_TTL_HOURS)*time.Hour, lgr)
+	trashPurger := worker.NewTrashPurger(songRepo,
+		time.Duration(conf.Trash.TRASH_RETENTION_HOURS)*time.Hour,
+		time.Duration(conf.Trash.TRASH_PURGE_INTERVAL_MINUTES)*time.Minute,
+		lgr)
+	keyPurger := worker.NewIdempotencyKeyPurger(idempotencyRepo,
+		time.Duration(conf.Idempotency.IDEMPOTENCY_PURGE_INTERVAL_MINUTES)*time.Minute,
+		lgr)
+	jobRunner := worker.NewJobRunner(jobRepo, songController, conf.Job, lgr)
+	workers := []worker.Worker{trashPurger, keyPurger, jobRunner}
+	if infoCacheRepo != nil {
+		workers = append(workers, worker.NewPurger("Song info cache purger",
+			time.Duration(conf.InfoCache.INFO_CACHE_PURGE_INTERVAL_MINUTES)*time.Minute,
+			infoCacheRepo.PurgeExpiredSongDetails, lgr))
+	}
+	return &Components{
+		SongHandler:  userHandler,
+		JobHandler:   jobHandler,
+		CacheHandler: cacheHandler,
+		Idempotency:  idempotency,
+		Workers:      workers,
+	}, nil
 }
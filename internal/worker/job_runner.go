@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/apperrors"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/audit"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/config"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/controller"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/model"
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+const maxRetryDelay = time.Hour
+
+type jobRunner struct {
+	repo           repository.JobRepository
+	songController controller.SongController
+	conf           config.JobConfig
+	lgr            *logger.Logger
+}
+
+// NewJobRunner processes queued song jobs with JOB_WORKERS goroutines.
+func NewJobRunner(repo repository.JobRepository, songController controller.SongController, conf config.JobConfig, lgr *logger.Logger) Worker {
+	return &jobRunner{
+		repo:           repo,
+		songController: songController,
+		conf:           conf,
+		lgr:            lgr,
+	}
+}
+
+func (jr *jobRunner) Run(ctx context.Context) {
+	if jr.conf.JOB_WORKERS < 1 {
+		jr.lgr.InfoLogger.Println("Job runner is disabled")
+		return
+	}
+	jr.lgr.InfoLogger.Printf("Job runner started with %d workers\n", jr.conf.JOB_WORKERS)
+	var wg sync.WaitGroup
+	for w := 0; w < jr.conf.JOB_WORKERS; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jr.work(ctx)
+		}()
+	}
+	wg.Wait()
+	jr.lgr.InfoLogger.Println("Job runner stopped")
+}
+
+// work claims jobs one after another and waits for the poll interval when
+// the queue is empty.
+func (jr *jobRunner) work(ctx context.Context) {
+	pollInterval := time.Duration(jr.conf.JOB_POLL_INTERVAL_SECONDS) * time.Second
+	lease := time.Duration(jr.conf.JOB_LEASE_SECONDS) * time.Second
+	for {
+		job, err := jr.repo.ClaimSongJob(ctx, lease)
+		if err == nil && job != nil {
+			jr.process(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (jr *jobRunner) process(ctx context.Context, job *model.SongJob) {
+	jr.lgr.DebugLogger.Printf("Processing job %d, attempt %d of %d\n", job.ID, job.Attempts, job.MaxAttempts)
+	runCtx := ctx
+	if timeout := jr.runTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	result, _, err := jr.songController.InsertSong(audit.WithEditor(runCtx, job.Editor), job.SongRequest(), job.RefreshExisting)
+	if err == nil {
+		jr.repo.CompleteSongJob(ctx, job.ID, result.SoundId, result.Sources)
+		return
+	}
+
+	reason := "unexpected error"
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		reason = appErr.Message
+	}
+	var retryAt *time.Time
+	if !isPermanent(err) && job.Attempts < job.MaxAttempts {
+		next := time.Now().Add(jr.retryDelay(job.Attempts))
+		retryAt = &next
+		jr.lgr.InfoLogger.Printf("Job %d failed, retrying at %s: %v\n", job.ID, next.Format(time.RFC3339), err)
+	} else {
+		jr.lgr.ErrorLogger.Printf("Job %d is dead after %d attempts: %v\n", job.ID, job.Attempts, err)
+	}
+	jr.repo.FailSongJob(ctx, job.ID, reason, retryAt)
+}
+
+// runTimeout ends an attempt before its lease runs out, so that no other
+// worker claims the job while it is still running.
+func (jr *jobRunner) runTimeout() time.Duration {
+	lease := time.Duration(jr.conf.JOB_LEASE_SECONDS) * time.Second
+	return lease - lease/10
+}
+
+// retryDelay doubles with every attempt, starting at JOB_RETRY_BASE_SECONDS.
+func (jr *jobRunner) retryDelay(attempts int) time.Duration {
+	delay := time.Duration(jr.conf.JOB_RETRY_BASE_SECONDS) * time.Second
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// isPermanent tells errors that will not go away by retrying.
+func isPermanent(err error) bool {
+	return errors.Is(err, apperrors.ErrConflict) ||
+		errors.Is(err, apperrors.ErrValidation) ||
+		errors.Is(err, apperrors.ErrUpstreamNotFound)
+}
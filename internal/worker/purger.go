@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/online_music_library/internal/repository"
+	"github.com/YurcheuskiRadzivon/online_music_library/pkg/logger"
+)
+
+// PurgeFunc removes stale rows and returns how many were removed.
+type PurgeFunc func(ctx context.Context) (int64, error)
+
+type purger struct {
+	name     string
+	interval time.Duration
+	purge    PurgeFunc
+	lgr      *logger.Logger
+}
+
+func NewPurger(name string, interval time.Duration, purge PurgeFunc, lgr *logger.Logger) Worker {
+	return &purger{
+		name:     name,
+		interval: interval,
+		purge:    purge,
+		lgr:      lgr,
+	}
+}
+
+// NewTrashPurger removes songs that have been in the trash longer than the
+// retention period.
+func NewTrashPurger(repo repository.SongRepository, retention time.Duration, interval time.Duration, lgr *logger.Logger) Worker {
+	return NewPurger("Trash purger", interval, func(ctx context.Context) (int64, error) {
+		return repo.PurgeDeletedSongs(ctx, time.Now().Add(-retention))
+	}, lgr)
+}
+
+// NewIdempotencyKeyPurger removes idempotency keys past their TTL.
+func NewIdempotencyKeyPurger(repo repository.IdempotencyRepository, interval time.Duration, lgr *logger.Logger) Worker {
+	return NewPurger("Idempotency key purger", interval, repo.PurgeExpiredKeys, lgr)
+}
+
+// Run purges once on start and then every interval, until ctx is done.
+func (p *purger) Run(ctx context.Context) {
+	if p.interval <= 0 {
+		p.lgr.InfoLogger.Printf("%s is disabled\n", p.name)
+		return
+	}
+	p.lgr.InfoLogger.Printf("%s started, interval: %s\n", p.name, p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.purge(ctx); err != nil {
+			p.lgr.ErrorLogger.Printf("%s failed: %v\n", p.name, err)
+		}
+		select {
+		case <-ctx.Done():
+			p.lgr.InfoLogger.Printf("%s stopped\n", p.name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
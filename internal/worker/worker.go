@@ -0,0 +1,8 @@
+package worker
+
+import "context"
+
+// Worker is a background task that runs until ctx is done.
+type Worker interface {
+	Run(ctx context.Context)
+}
@@ -0,0 +1,25 @@
+-- Songs with the same group and title (ignoring case) are not merged or
+-- trashed automatically: an editor has to decide which one to keep. Until
+-- then the migration fails and lists them; afterwards force the migration
+-- version back to 9 and run the migrations again.
+DO
+$$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(format('%s - %s (IDs %s)', d."group", d.song, d.ids), '; ')
+    INTO duplicates
+    FROM (SELECT min("group") AS "group", min(song) AS song, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+          FROM songs
+          WHERE deleted_at IS NULL
+          GROUP BY lower("group"), lower(song)
+          HAVING count(*) > 1) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'duplicate songs must be merged or deleted before adding the unique index: %', duplicates
+            USING HINT = 'Delete or rename the duplicates, then force the migration version to 9 and migrate again.';
+    END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_songs_group_song_unique ON songs (lower("group"), lower(song)) WHERE deleted_at IS NULL;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS song_jobs (
+    id               BIGSERIAL PRIMARY KEY,
+    status           VARCHAR(16)  NOT NULL DEFAULT 'queued',
+    "group"          VARCHAR(255) NOT NULL,
+    song             VARCHAR(255) NOT NULL,
+    refresh_existing BOOLEAN      NOT NULL DEFAULT FALSE,
+    editor           VARCHAR(255),
+    attempts         INTEGER      NOT NULL DEFAULT 0,
+    max_attempts     INTEGER      NOT NULL,
+    last_error       TEXT,
+    song_id          INTEGER REFERENCES songs (id) ON DELETE SET NULL,
+    run_at           TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    locked_until     TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_jobs_pending ON song_jobs (run_at, id) WHERE status IN ('queued', 'running');
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS song_info_cache (
+    "group"      VARCHAR(255) NOT NULL,
+    song         VARCHAR(255) NOT NULL,
+    found        BOOLEAN      NOT NULL,
+    release_date DATE,
+    text         TEXT,
+    link         VARCHAR(255),
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY ("group", song)
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_info_cache_expires_at ON song_info_cache (expires_at);
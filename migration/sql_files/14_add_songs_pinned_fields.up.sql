@@ -0,0 +1,2 @@
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS pinned_fields TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,5 @@
+ALTER TABLE song_jobs
+    DROP COLUMN IF EXISTS mode,
+    DROP COLUMN IF EXISTS release_date,
+    DROP COLUMN IF EXISTS text,
+    DROP COLUMN IF EXISTS link;
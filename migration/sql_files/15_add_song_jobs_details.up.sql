@@ -0,0 +1,5 @@
+ALTER TABLE song_jobs
+    ADD COLUMN IF NOT EXISTS mode         VARCHAR(32),
+    ADD COLUMN IF NOT EXISTS release_date DATE,
+    ADD COLUMN IF NOT EXISTS text         TEXT,
+    ADD COLUMN IF NOT EXISTS link         VARCHAR(255);
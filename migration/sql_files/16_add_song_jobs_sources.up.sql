@@ -0,0 +1,2 @@
+ALTER TABLE song_jobs
+    ADD COLUMN IF NOT EXISTS sources JSONB;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_songs_release_date_key;
+DROP INDEX IF EXISTS idx_songs_text_length;
+DROP INDEX IF EXISTS idx_songs_song_lower;
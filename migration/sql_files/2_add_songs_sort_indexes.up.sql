@@ -0,0 +1,11 @@
+CREATE INDEX IF NOT EXISTS idx_songs_song_lower ON songs (lower(song), id);
+
+CREATE INDEX IF NOT EXISTS idx_songs_text_length ON songs (COALESCE(length(text), 0), id);
+
+CREATE INDEX IF NOT EXISTS idx_songs_release_date_key ON songs (
+    COALESCE(CASE
+                 WHEN release_date ~ '^[0-9]{2}\.[0-9]{2}\.[0-9]{4}$'
+                     THEN substr(release_date, 7, 4) || substr(release_date, 4, 2) || substr(release_date, 1, 2)
+                 END, ''),
+    id
+);
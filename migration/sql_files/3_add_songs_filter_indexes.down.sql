@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_songs_song_lower_pattern;
+DROP INDEX IF EXISTS idx_songs_group_lower;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_songs_group_lower ON songs (lower("group"));
+
+CREATE INDEX IF NOT EXISTS idx_songs_song_lower_pattern ON songs (lower(song) text_pattern_ops);
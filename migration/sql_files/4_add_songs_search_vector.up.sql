@@ -0,0 +1,8 @@
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(song, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE("group", '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(text, '')), 'C')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_songs_search_vector ON songs USING GIN (search_vector);
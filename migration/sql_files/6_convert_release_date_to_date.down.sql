@@ -0,0 +1,30 @@
+DROP INDEX IF EXISTS idx_songs_release_date;
+
+ALTER TABLE songs
+    ADD COLUMN release_date_raw VARCHAR(10);
+
+UPDATE songs
+SET release_date_raw = to_char(release_date, 'DD.MM.YYYY')
+WHERE release_date IS NOT NULL;
+
+UPDATE songs
+SET release_date_raw = failures.raw_value
+FROM release_date_backfill_failures AS failures
+WHERE failures.song_id = songs.id
+  AND songs.release_date IS NULL;
+
+ALTER TABLE songs
+    DROP COLUMN release_date;
+
+ALTER TABLE songs
+    RENAME COLUMN release_date_raw TO release_date;
+
+CREATE INDEX IF NOT EXISTS idx_songs_release_date_key ON songs (
+    COALESCE(CASE
+                 WHEN release_date ~ '^[0-9]{2}\.[0-9]{2}\.[0-9]{4}$'
+                     THEN substr(release_date, 7, 4) || substr(release_date, 4, 2) || substr(release_date, 1, 2)
+                 END, ''),
+    id
+);
+
+DROP TABLE IF EXISTS release_date_backfill_failures;
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS release_date_backfill_failures
+(
+    song_id     INTEGER PRIMARY KEY,
+    raw_value   VARCHAR(10) NOT NULL,
+    reported_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE OR REPLACE FUNCTION parse_release_date(value TEXT) RETURNS DATE AS
+$$
+BEGIN
+    IF value ~ '^[0-9]{2}\.[0-9]{2}\.[0-9]{4}$' THEN
+        RETURN make_date(substr(value, 7, 4)::INTEGER, substr(value, 4, 2)::INTEGER, substr(value, 1, 2)::INTEGER);
+    ELSIF value ~ '^[0-9]{4}-[0-9]{2}-[0-9]{2}$' THEN
+        RETURN make_date(substr(value, 1, 4)::INTEGER, substr(value, 6, 2)::INTEGER, substr(value, 9, 2)::INTEGER);
+    END IF;
+    RETURN NULL;
+EXCEPTION
+    WHEN OTHERS THEN
+        RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+ALTER TABLE songs
+    ADD COLUMN release_date_parsed DATE;
+
+UPDATE songs
+SET release_date_parsed = parse_release_date(trim(release_date))
+WHERE trim(COALESCE(release_date, '')) <> '';
+
+INSERT INTO release_date_backfill_failures (song_id, raw_value)
+SELECT id, release_date
+FROM songs
+WHERE trim(COALESCE(release_date, '')) <> ''
+  AND release_date_parsed IS NULL
+ON CONFLICT (song_id) DO UPDATE SET raw_value = excluded.raw_value, reported_at = now();
+
+DROP INDEX IF EXISTS idx_songs_release_date_key;
+
+ALTER TABLE songs
+    DROP COLUMN release_date;
+
+ALTER TABLE songs
+    RENAME COLUMN release_date_parsed TO release_date;
+
+CREATE INDEX IF NOT EXISTS idx_songs_release_date ON songs (COALESCE(release_date, '-infinity'::DATE), id);
+
+DROP FUNCTION parse_release_date(TEXT);
@@ -0,0 +1,4 @@
+ALTER TABLE songs
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs (deleted_at, id) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS song_revisions (
+    song_id      INTEGER      NOT NULL REFERENCES songs (id) ON DELETE CASCADE,
+    revision     INTEGER      NOT NULL,
+    action       VARCHAR(16)  NOT NULL,
+    "group"      VARCHAR(255) NOT NULL,
+    song         VARCHAR(255) NOT NULL,
+    release_date DATE,
+    text         TEXT,
+    link         VARCHAR(255),
+    editor       VARCHAR(255),
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (song_id, revision)
+);
+
+INSERT INTO song_revisions (song_id, revision, action, "group", song, release_date, text, link)
+SELECT id, version, 'import', "group", song, release_date, text, link
+FROM songs
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,109 @@
+// Package linediff computes a line-level diff of two texts.
+package linediff
+
+import "strings"
+
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// MaxLines bounds the lines Diff compares with each other, since the longest
+// common subsequence takes memory proportional to the product of both line
+// counts. Longer texts are diffed as a whole replacement of the lines
+// between their common beginning and end.
+const MaxLines = 1000
+
+// Diff returns the lines of both texts in order, each marked as kept,
+// removed from a or added in b. It is based on the longest common
+// subsequence, which is plenty for song lyrics.
+func Diff(a string, b string) []Line {
+	oldLines := splitLines(a)
+	newLines := splitLines(b)
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(oldLines)+len(newLines))
+	for _, line := range oldLines[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: line})
+	}
+	oldMiddle := oldLines[prefix : len(oldLines)-suffix]
+	newMiddle := newLines[prefix : len(newLines)-suffix]
+	if len(oldMiddle) > MaxLines || len(newMiddle) > MaxLines {
+		lines = appendReplace(lines, oldMiddle, newMiddle)
+	} else {
+		lines = appendLCS(lines, oldMiddle, newMiddle)
+	}
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: line})
+	}
+	return lines
+}
+
+func appendLCS(lines []Line, oldLines []string, newLines []string) []Line {
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			lines = append(lines, Line{Op: Equal, Text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Op: Delete, Text: oldLines[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, Text: newLines[j]})
+			j++
+		}
+	}
+	return appendReplace(lines, oldLines[i:], newLines[j:])
+}
+
+func appendReplace(lines []Line, oldLines []string, newLines []string) []Line {
+	for _, line := range oldLines {
+		lines = append(lines, Line{Op: Delete, Text: line})
+	}
+	for _, line := range newLines {
+		lines = append(lines, Line{Op: Insert, Text: line})
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
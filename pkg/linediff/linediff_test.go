@@ -0,0 +1,103 @@
+package linediff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{
+			name: "both empty",
+			a:    "",
+			b:    "",
+			want: []Line{},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "one\ntwo",
+			want: []Line{{Insert, "one"}, {Insert, "two"}},
+		},
+		{
+			name: "to empty",
+			a:    "one\ntwo",
+			b:    "",
+			want: []Line{{Delete, "one"}, {Delete, "two"}},
+		},
+		{
+			name: "identical",
+			a:    "one\ntwo\nthree",
+			b:    "one\ntwo\nthree",
+			want: []Line{{Equal, "one"}, {Equal, "two"}, {Equal, "three"}},
+		},
+		{
+			name: "insert only",
+			a:    "one\nthree",
+			b:    "zero\none\ntwo\nthree\nfour",
+			want: []Line{{Insert, "zero"}, {Equal, "one"}, {Insert, "two"}, {Equal, "three"}, {Insert, "four"}},
+		},
+		{
+			name: "delete only",
+			a:    "zero\none\ntwo\nthree\nfour",
+			b:    "one\nthree",
+			want: []Line{{Delete, "zero"}, {Equal, "one"}, {Delete, "two"}, {Equal, "three"}, {Delete, "four"}},
+		},
+		{
+			name: "changed line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []Line{{Equal, "one"}, {Delete, "two"}, {Insert, "2"}, {Equal, "three"}},
+		},
+		{
+			name: "too many lines are replaced as a whole",
+			a:    "intro\n" + numberedLines("old", MaxLines+1) + "\noutro",
+			b:    "intro\n" + numberedLines("new", 2) + "\noutro",
+			want: append(append(append([]Line{{Equal, "intro"}},
+				lineRange(Delete, "old", MaxLines+1)...),
+				lineRange(Insert, "new", 2)...),
+				Line{Equal, "outro"}),
+		},
+		{
+			name: "long texts with a short change are diffed",
+			a:    numberedLines("line", 2*MaxLines) + "\nold",
+			b:    numberedLines("line", 2*MaxLines) + "\nnew",
+			want: append(lineRange(Equal, "line", 2*MaxLines), Line{Delete, "old"}, Line{Insert, "new"}),
+		},
+		{
+			name: "windows line endings",
+			a:    "one\r\ntwo",
+			b:    "one\ntwo",
+			want: []Line{{Equal, "one"}, {Equal, "two"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func numberedLines(prefix string, n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s %d", prefix, i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func lineRange(op Op, prefix string, n int) []Line {
+	lines := make([]Line, n)
+	for i := range lines {
+		lines[i] = Line{op, fmt.Sprintf("%s %d", prefix, i)}
+	}
+	return lines
+}
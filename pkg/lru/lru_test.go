@@ -0,0 +1,130 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+type cacheStep struct {
+	op    string // set, get or wait
+	key   string
+	value int
+	ttl   time.Duration
+	want  bool // get: the key is cached
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		steps    []cacheStep
+		wantLen  int
+	}{
+		{
+			name:     "get after set",
+			capacity: 2,
+			steps: []cacheStep{
+				{op: "set", key: "a", value: 1, ttl: time.Minute},
+				{op: "get", key: "a", value: 1, want: true},
+				{op: "get", key: "b", want: false},
+			},
+			wantLen: 1,
+		},
+		{
+			name:     "evicts the least recently set",
+			capacity: 2,
+			steps: []cacheStep{
+				{op: "set", key: "a", value: 1, ttl: time.Minute},
+				{op: "set", key: "b", value: 2, ttl: time.Minute},
+				{op: "set", key: "c", value: 3, ttl: time.Minute},
+				{op: "get", key: "a", want: false},
+				{op: "get", key: "b", value: 2, want: true},
+				{op: "get", key: "c", value: 3, want: true},
+			},
+			wantLen: 2,
+		},
+		{
+			name:     "get keeps an entry from eviction",
+			capacity: 2,
+			steps: []cacheStep{
+				{op: "set", key: "a", value: 1, ttl: time.Minute},
+				{op: "set", key: "b", value: 2, ttl: time.Minute},
+				{op: "get", key: "a", value: 1, want: true},
+				{op: "set", key: "c", value: 3, ttl: time.Minute},
+				{op: "get", key: "a", value: 1, want: true},
+				{op: "get", key: "b", want: false},
+			},
+			wantLen: 2,
+		},
+		{
+			name:     "set replaces the value and the ttl",
+			capacity: 2,
+			steps: []cacheStep{
+				{op: "set", key: "a", value: 1, ttl: time.Minute},
+				{op: "set", key: "a", value: 2, ttl: time.Hour},
+				{op: "wait", ttl: 2 * time.Minute},
+				{op: "get", key: "a", value: 2, want: true},
+			},
+			wantLen: 1,
+		},
+		{
+			name:     "expired entries are removed",
+			capacity: 2,
+			steps: []cacheStep{
+				{op: "set", key: "a", value: 1, ttl: time.Minute},
+				{op: "set", key: "b", value: 2, ttl: time.Hour},
+				{op: "wait", ttl: time.Minute},
+				{op: "get", key: "a", value: 1, want: true},
+				{op: "wait", ttl: time.Second},
+				{op: "get", key: "a", want: false},
+				{op: "get", key: "b", value: 2, want: true},
+			},
+			wantLen: 1,
+		},
+		{
+			name:     "zero ttl is not cached",
+			capacity: 2,
+			steps: []cacheStep{
+				{op: "set", key: "a", value: 1, ttl: 0},
+				{op: "get", key: "a", want: false},
+			},
+			wantLen: 0,
+		},
+		{
+			name:     "zero capacity disables the cache",
+			capacity: 0,
+			steps: []cacheStep{
+				{op: "set", key: "a", value: 1, ttl: time.Minute},
+				{op: "get", key: "a", want: false},
+			},
+			wantLen: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			c := New[string, int](tt.capacity)
+			c.now = func() time.Time { return now }
+
+			for i, step := range tt.steps {
+				switch step.op {
+				case "set":
+					c.Set(step.key, step.value, step.ttl)
+				case "get":
+					value, ok := c.Get(step.key)
+					if ok != step.want || (ok && value != step.value) {
+						t.Fatalf("step %d: Get(%q) = %d, %v, want %d, %v", i, step.key, value, ok, step.value, step.want)
+					}
+				case "wait":
+					now = now.Add(step.ttl)
+				default:
+					t.Fatalf("step %d: unknown op %q", i, step.op)
+				}
+			}
+			if got := c.Len(); got != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+			}
+		})
+	}
+}